@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Проверить конфигурацию и вывести итоговые значения без секретов",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+
+			dump, err := cfg.Dump()
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), dump)
+			return nil
+		},
+	}
+}
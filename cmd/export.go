@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/spf13/cobra"
+)
+
+// exportPageSize совпадает с максимальным лимитом ListSubscriptions
+const exportPageSize = 100
+
+func newExportCmd() *cobra.Command {
+	var format, output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Выгрузить все подписки в JSON или CSV",
+		Long: `Выгружает все подписки. JSON содержит подписки целиком, с историей цен, скидками, участниками,
+паузами и причинами отмены, — это формат начальных данных storage.seed_file.
+CSV — только для просмотра в таблицах: в нем одна текущая цена, без истории цен, скидок, участников,
+пауз и причин отмены. Import такой файл не принимает: загруженный обратно, он пересчитал бы прошлые месяцы
+по текущей цене.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" && format != "csv" {
+				return fmt.Errorf("unknown format %q, expected json or csv", format)
+			}
+
+			a, err := newApp(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer a.Close()
+
+			// Страницы идут от последней выгруженной подписки, а не по OFFSET: подписки, созданные
+			// или удаленные во время выгрузки, не сдвигают следующие страницы
+			var subscriptions []*entity.Subscription
+			req := &entity.ListSubscriptionsRequest{Limit: exportPageSize}
+			for {
+				page, err := a.services.SubscriptionService.ListSubscriptions(cmd.Context(), req)
+				if err != nil {
+					return err
+				}
+				subscriptions = append(subscriptions, page...)
+				if len(page) < exportPageSize {
+					break
+				}
+				last := page[len(page)-1]
+				req = &entity.ListSubscriptionsRequest{Limit: exportPageSize, After: &entity.ListCursor{StartDate: last.StartDate, ID: last.ID}}
+			}
+
+			w := cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if format == "csv" {
+				return writeExportCSV(w, subscriptions)
+			}
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(subscriptions)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "json", "формат выгрузки: json или csv")
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "файл для выгрузки, - для stdout")
+
+	return cmd
+}
+
+// writeExportCSV пишет подписки с текущей ценой; теги разделены ";". Колонка id отличает выгрузку
+// от файла для import, который ее не принимает (см. readImportCSV).
+func writeExportCSV(w io.Writer, subscriptions []*entity.Subscription) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "service_name", "price", "user_id", "start_date", "end_date", "trial_end_date", "billing_day", "tags"}); err != nil {
+		return err
+	}
+
+	for _, s := range subscriptions {
+		endDate, trialEndDate, billingDay := "", "", ""
+		if s.EndDate != nil {
+			endDate = s.EndDate.Format(entity.DateLayout)
+		}
+		if s.TrialEndDate != nil {
+			trialEndDate = s.TrialEndDate.Format(entity.MonthLayout)
+		}
+		if s.BillingDay != nil {
+			billingDay = strconv.Itoa(*s.BillingDay)
+		}
+		err := writer.Write([]string{
+			s.ID.String(),
+			s.ServiceName,
+			strconv.Itoa(s.Price),
+			s.UserID.String(),
+			s.StartDate.Format(entity.DateLayout),
+			endDate,
+			trialEndDate,
+			billingDay,
+			strings.Join(s.Tags, ";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/internal/service"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd() *cobra.Command {
+	var createUsers bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Импортировать подписки из JSON или CSV файла",
+		Long: `Импортирует подписки через SubscriptionService с той же валидацией, что и POST /subscriptions.
+JSON — массив объектов CreateSubscriptionRequest, CSV — файл с заголовком
+service_name,price,user_id,start_date,end_date,trial_end_date,billing_day,tags (теги через ";"; лишние колонки игнорируются).
+CSV из export (с колонкой id) не принимается: в нем нет истории цен, скидок, участников и пауз.
+Пользователи должны существовать; флаг --create-users заводит недостающих с профилем по умолчанию.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			requests, err := readImportFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			a, err := newApp(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer a.Close()
+
+			var imported, failed int
+			for i, req := range requests {
+				err := binding.Validator.ValidateStruct(req)
+				if err == nil && createUsers {
+					err = ensureUser(cmd.Context(), a.services.UserService, req.UserID)
+					for _, p := range req.Participants {
+						if err == nil {
+							err = ensureUser(cmd.Context(), a.services.UserService, p.UserID)
+						}
+					}
+				}
+				if err == nil {
+					_, err = a.services.SubscriptionService.CreateSubscription(cmd.Context(), req)
+				}
+				if err != nil {
+					failed++
+					fmt.Fprintf(cmd.ErrOrStderr(), "record %d: %v\n", i+1, err)
+					continue
+				}
+				imported++
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "imported: %d, failed: %d\n", imported, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d records failed to import", failed, len(requests))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&createUsers, "create-users", false, "создать пользователей, которых еще нет")
+
+	return cmd
+}
+
+// ensureUser заводит пользователя с профилем по умолчанию, если его еще нет
+func ensureUser(ctx context.Context, users service.UserService, id uuid.UUID) error {
+	_, err := users.GetUser(repository.WithPrimary(ctx), id)
+	if err == nil || err.Error() != "user not found" {
+		return err
+	}
+
+	_, err = users.CreateUser(ctx, &entity.CreateUserRequest{ID: &id})
+	if err != nil && err.Error() == "user already exists" {
+		return nil
+	}
+	return err
+}
+
+func readImportFile(path string) ([]*entity.CreateSubscriptionRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readImportCSV(f)
+	}
+
+	var requests []*entity.CreateSubscriptionRequest
+	if err := json.NewDecoder(f).Decode(&requests); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	// service_id из выгрузки другой базы не имеет смысла — сервис определяется по названию
+	for _, req := range requests {
+		if req.ServiceName != "" {
+			req.ServiceID = nil
+		}
+	}
+	return requests, nil
+}
+
+func readImportCSV(r io.Reader) ([]*entity.CreateSubscriptionRequest, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	// Выгрузка хранит только текущую цену: импорт пересчитал бы по ней прошлые месяцы и потерял
+	// скидки, участников и паузы
+	if _, ok := columns["id"]; ok {
+		return nil, fmt.Errorf("csv looks like an export: it has no price history, discounts, participants or pauses; use a json export as storage.seed_file instead")
+	}
+	for _, name := range []string{"service_name", "price", "user_id", "start_date"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header has no %q column", name)
+		}
+	}
+
+	var requests []*entity.CreateSubscriptionRequest
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		price, err := strconv.Atoi(record[columns["price"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price: %w", line, err)
+		}
+		userID, err := uuid.Parse(record[columns["user_id"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid user_id: %w", line, err)
+		}
+
+		req := &entity.CreateSubscriptionRequest{
+			ServiceName: record[columns["service_name"]],
+			Price:       price,
+			UserID:      userID,
+			StartDate:   record[columns["start_date"]],
+		}
+		if i, ok := columns["end_date"]; ok && record[i] != "" {
+			endDate := record[i]
+			req.EndDate = &endDate
+		}
+		if i, ok := columns["trial_end_date"]; ok && record[i] != "" {
+			trialEndDate := record[i]
+			req.TrialEndDate = &trialEndDate
+		}
+		if i, ok := columns["billing_day"]; ok && record[i] != "" {
+			billingDay, err := strconv.Atoi(record[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid billing_day: %w", line, err)
+			}
+			req.BillingDay = &billingDay
+		}
+		if i, ok := columns["tags"]; ok && record[i] != "" {
+			req.Tags = strings.Split(record[i], ";")
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, nil
+}
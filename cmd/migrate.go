@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/migrations"
+	"github.com/ShekleinAleksey/subscriptions/pkg/postgres"
+	"github.com/ShekleinAleksey/subscriptions/pkg/sqlite"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Управление схемой БД встроенными миграциями",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Применить все новые миграции",
+			Args:  cobra.NoArgs,
+			RunE: withMigrator(func(m *migrations.Migrator, args []string) error {
+				return m.Up()
+			}),
+		},
+		&cobra.Command{
+			Use:   "down [N]",
+			Short: "Откатить N последних миграций (по умолчанию одну)",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: withMigrator(func(m *migrations.Migrator, args []string) error {
+				steps := 1
+				if len(args) > 0 {
+					var err error
+					steps, err = strconv.Atoi(args[0])
+					if err != nil || steps <= 0 {
+						return fmt.Errorf("invalid number of steps %q", args[0])
+					}
+				}
+				return m.Down(steps)
+			}),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Показать текущую версию схемы",
+			Args:  cobra.NoArgs,
+			RunE: withMigrator(func(m *migrations.Migrator, args []string) error {
+				return nil
+			}),
+		},
+		&cobra.Command{
+			Use:   "force VERSION",
+			Short: "Выставить версию схемы без выполнения миграций и снять флаг dirty",
+			Args:  cobra.ExactArgs(1),
+			RunE: withMigrator(func(m *migrations.Migrator, args []string) error {
+				version, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				return m.Force(version)
+			}),
+		},
+	)
+
+	return cmd
+}
+
+// withMigrator открывает БД по обычному конфигу, выполняет действие и печатает итоговую версию схемы
+func withMigrator(fn func(m *migrations.Migrator, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		a, err := newApp(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		migrator, err := a.newMigrator(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer migrator.Close()
+
+		if err := fn(migrator, args); err != nil {
+			return err
+		}
+
+		status, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		if !status.Applied {
+			fmt.Fprintf(cmd.OutOrStdout(), "version: none, latest: %d\n", status.Latest)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "version: %d, dirty: %t, latest: %d\n", status.Version, status.Dirty, status.Latest)
+
+		return nil
+	}
+}
+
+// newMigrator создает мигратор для выбранного в конфиге хранилища
+func (a *app) newMigrator(ctx context.Context) (*migrations.Migrator, error) {
+	switch a.cfg.Storage.Driver {
+	case config.StoragePostgres:
+		return postgres.NewMigrator(ctx, a.db)
+	case config.StorageSQLite:
+		return sqlite.NewMigrator(a.cfg.Storage.SQLitePath)
+	default:
+		return nil, fmt.Errorf("migrations are not supported by %s storage", a.cfg.Storage.Driver)
+	}
+}
+
+// applyMigrations применяет все новые миграции при старте сервера
+func (a *app) applyMigrations(ctx context.Context) error {
+	migrator, err := a.newMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up()
+}
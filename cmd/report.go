@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Отчеты по подпискам",
+	}
+
+	cmd.AddCommand(newReportSummaryCmd())
+
+	return cmd
+}
+
+func newReportSummaryCmd() *cobra.Command {
+	var user, serviceName, from, to string
+	var tags []string
+	var asJSON, byTag bool
+
+	cmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Суммарная стоимость подписок за период",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req entity.SubscriptionSummaryRequest
+			if user != "" {
+				userID, err := uuid.Parse(user)
+				if err != nil {
+					return fmt.Errorf("invalid user: %w", err)
+				}
+				req.UserID = &userID
+			}
+			if serviceName != "" {
+				req.ServiceName = &serviceName
+			}
+			if from != "" {
+				req.StartPeriod = &from
+			}
+			if to != "" {
+				req.EndPeriod = &to
+			}
+			req.Tags = tags
+
+			a, err := newApp(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer a.Close()
+
+			if byTag {
+				spend, err := a.services.SubscriptionService.GetSpendByTag(cmd.Context(), &req)
+				if err != nil {
+					return err
+				}
+				if asJSON {
+					return json.NewEncoder(cmd.OutOrStdout()).Encode(spend)
+				}
+				for _, s := range spend {
+					tag := s.Tag
+					if tag == "" {
+						tag = "(no tags)"
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d\t%d\n", tag, s.TotalCost, s.Count)
+				}
+				return nil
+			}
+
+			summary, err := a.services.SubscriptionService.GetSubscriptionSummary(cmd.Context(), &req)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(summary)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "total cost: %d\nsubscriptions: %d\n", summary.TotalCost, summary.Count)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&user, "user", "", "ID пользователя")
+	cmd.Flags().StringVar(&serviceName, "service", "", "название сервиса")
+	cmd.Flags().StringVar(&from, "from", "", "начало периода (MM-YYYY)")
+	cmd.Flags().StringVar(&to, "to", "", "конец периода (MM-YYYY)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "только подписки со всеми указанными тегами")
+	cmd.Flags().BoolVar(&byTag, "by-tag", false, "разбить стоимость по тегам")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "вывести результат в JSON")
+	cmd.MarkFlagsRequiredTogether("from", "to")
+
+	return cmd
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/internal/service"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/ShekleinAleksey/subscriptions/pkg/postgres"
+	"github.com/ShekleinAleksey/subscriptions/pkg/sqlite"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// configPath задается глобальным флагом --config и используется всеми командами
+var configPath string
+
+func newRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "subscription",
+		Short:        "Сервис агрегации данных об онлайн-подписках",
+		SilenceUsage: true,
+		// Без подкоманды запускается HTTP-сервер, как и раньше
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&configPath, "config", os.Getenv("CONFIG_PATH"),
+		"путь к YAML-конфигу (по умолчанию "+config.DefaultPath+")")
+
+	cmd.AddCommand(
+		newServeCmd(),
+		newConfigCmd(),
+		newMigrateCmd(),
+		newImportCmd(),
+		newExportCmd(),
+		newReportCmd(),
+		newSeedCmd(),
+	)
+
+	return cmd
+}
+
+// app — общие зависимости команд: конфигурация, соединение с БД, репозитории и сервисы.
+// db равна nil, если выбрано хранилище в памяти; replica есть только у postgres.
+type app struct {
+	cfg      config.Config
+	db       *sqlx.DB
+	replica  *sqlx.DB
+	repo     *repository.Repository
+	services *service.Service
+}
+
+func newApp(ctx context.Context) (*app, error) {
+	// Загрузка конфигурации
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.SetLogrus(cfg.Log.Level)
+	if dump, err := cfg.Dump(); err == nil {
+		logrus.Debugf("Effective config:\n%s", dump)
+	}
+
+	a := &app{cfg: cfg}
+	if err := a.openStorage(ctx); err != nil {
+		return nil, err
+	}
+
+	logrus.Debug("Initializing service...")
+	a.services = service.NewService(a.repo, a.cfg.Subscriptions)
+
+	return a, nil
+}
+
+// openStorage создает репозитории выбранного в конфиге хранилища
+func (a *app) openStorage(ctx context.Context) error {
+	logrus.Debugf("Initializing %s repository...", a.cfg.Storage.Driver)
+
+	if a.cfg.Storage.Driver == config.StorageMemory {
+		var seed []*entity.Subscription
+		if a.cfg.Storage.SeedFile != "" {
+			var err error
+			if seed, err = repository.LoadSeed(a.cfg.Storage.SeedFile); err != nil {
+				return err
+			}
+		}
+
+		repo, err := repository.NewMemoryRepository(seed)
+		if err != nil {
+			return err
+		}
+		a.repo = repo
+		return nil
+	}
+
+	if a.cfg.Storage.Driver == config.StorageSQLite {
+		db, err := sqlite.NewDB(ctx, a.cfg.Storage.SQLitePath)
+		if err != nil {
+			return fmt.Errorf("error opening database: %w", err)
+		}
+		a.db = db
+		a.repo = repository.NewSQLiteRepository(db)
+		return nil
+	}
+
+	db, err := postgres.NewDB(ctx, a.cfg)
+	if err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+
+	replica, err := postgres.NewReplicaDB(ctx, a.cfg)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("error opening read replica: %w", err)
+	}
+
+	a.db, a.replica = db, replica
+	a.repo = repository.NewRepository(db, replica)
+	return nil
+}
+
+func (a *app) Close() {
+	if a.db != nil {
+		if err := a.db.Close(); err != nil {
+			logrus.Errorf("Error closing database: %v", err)
+		}
+	}
+	if a.replica != nil {
+		if err := a.replica.Close(); err != nil {
+			logrus.Errorf("Error closing read replica: %v", err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// seedServices — сервисы и типичные цены для демонстрационных данных
+var seedServices = []struct {
+	name  string
+	price int
+}{
+	{"Yandex Plus", 399},
+	{"Kinopoisk", 299},
+	{"Amediateka", 599},
+	{"Netflix", 999},
+	{"Spotify", 299},
+	{"YouTube Premium", 299},
+	{"iCloud", 149},
+	{"VK Music", 199},
+}
+
+func newSeedCmd() *cobra.Command {
+	var count, users int
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Заполнить базу демонстрационными подписками",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if count <= 0 || users <= 0 {
+				return fmt.Errorf("count and users must be positive")
+			}
+
+			a, err := newApp(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer a.Close()
+
+			userIDs := make([]uuid.UUID, users)
+			for i := range userIDs {
+				user, err := a.services.UserService.CreateUser(cmd.Context(), &entity.CreateUserRequest{
+					DisplayName: fmt.Sprintf("Demo user %d", i+1),
+				})
+				if err != nil {
+					return err
+				}
+				userIDs[i] = user.ID
+			}
+
+			// Периоды подписок одного пользователя на один сервис не пересекаются, иначе при
+			// subscriptions.overlap_policy = reject создание отклонялось бы. free — первый свободный
+			// месяц пары; пара без end_date занята навсегда, и новые подписки на нее не создаются.
+			type pair struct {
+				user    uuid.UUID
+				service int
+			}
+			free := make(map[pair]time.Time)
+			closed := make(map[pair]bool)
+
+			now := entity.MonthStart(time.Now())
+			created := 0
+			for attempt := 0; created < count && attempt < count*10; attempt++ {
+				p := pair{user: userIDs[rand.IntN(len(userIDs))], service: rand.IntN(len(seedServices))}
+				if closed[p] {
+					continue
+				}
+
+				start := now.AddDate(0, -rand.IntN(24), 0)
+				if next, ok := free[p]; ok {
+					start = next.AddDate(0, rand.IntN(3), 0)
+				}
+				svc := seedServices[p.service]
+				req := &entity.CreateSubscriptionRequest{
+					ServiceName: svc.name,
+					Price:       svc.price,
+					UserID:      p.user,
+					StartDate:   start.Format("01-2006"),
+				}
+				// Примерно треть подписок уже завершена или завершится
+				if rand.IntN(3) == 0 {
+					end := start.AddDate(0, rand.IntN(12), 0)
+					endDate := end.Format("01-2006")
+					req.EndDate = &endDate
+					free[p] = end.AddDate(0, 1, 0)
+				} else {
+					closed[p] = true
+				}
+
+				if _, err := a.services.SubscriptionService.CreateSubscription(cmd.Context(), req); err != nil {
+					return err
+				}
+				created++
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "seeded %d subscriptions for %d users\n", created, users)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&count, "count", 50, "количество подписок")
+	cmd.Flags().IntVar(&users, "users", 5, "количество пользователей")
+
+	return cmd
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/handler"
+	"github.com/ShekleinAleksey/subscriptions/internal/metrics"
+	"github.com/ShekleinAleksey/subscriptions/internal/worker"
+	"github.com/ShekleinAleksey/subscriptions/pkg/tracing"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Запустить HTTP-сервер",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd.Context())
+		},
+	}
+}
+
+// runServe запускает сервер и останавливает его после отмены ctx (SIGINT/SIGTERM)
+func runServe(ctx context.Context) error {
+	a, err := newApp(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if a.cfg.Migrate.OnStartup && a.db != nil {
+		logrus.Info("Applying migrations...")
+		if err := a.applyMigrations(ctx); err != nil {
+			return err
+		}
+	}
+
+	shutdownTracing, err := tracing.Init(ctx, a.cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("error initializing tracing: %w", err)
+	}
+
+	if a.db != nil {
+		metrics.RegisterDBStats(a.db.DB, a.cfg.Storage.Driver)
+	}
+	if a.replica != nil {
+		metrics.RegisterDBStats(a.replica.DB, "replica")
+	}
+	metrics.MustRegister(metrics.NewBusinessCollector(a.services.SubscriptionService))
+
+	logrus.Info("Initializing handler...")
+	handlers := handler.NewHandler(a.services, a.cfg.Auth)
+
+	srv := &http.Server{
+		Addr:         a.cfg.Server.Addr,
+		Handler:      handlers.InitRoutes(),
+		ReadTimeout:  a.cfg.Server.ReadTimeout,
+		WriteTimeout: a.cfg.Server.WriteTimeout,
+		IdleTimeout:  a.cfg.Server.IdleTimeout,
+	}
+
+	if a.cfg.Workers.Enabled {
+		logrus.Infof("Starting background workers every %s", a.cfg.Workers.Interval)
+		go worker.Run(ctx, a.cfg.Workers.Interval, a.jobs()...)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logrus.Infof("Server started at %s", a.cfg.Server.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("error starting server: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Сначала сообщаем о неготовности, затем дожидаемся завершения активных запросов
+	logrus.Info("Shutting down server...")
+	a.services.HealthService.SetShuttingDown()
+	time.Sleep(a.cfg.Server.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logrus.Errorf("Error shutting down server: %v", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logrus.Errorf("Error flushing traces: %v", err)
+	}
+
+	logrus.Info("Server stopped")
+	return nil
+}
+
+// jobs — фоновые задачи, которые serve запускает при workers.enabled
+func (a *app) jobs() []worker.Job {
+	return []worker.Job{{
+		Name: "trial_reminders",
+		Run: func(ctx context.Context) error {
+			_, err := a.services.EventService.RemindTrialsEnding(ctx, time.Now(), a.cfg.Workers.TrialReminderBefore)
+			return err
+		},
+	}}
+}
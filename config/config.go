@@ -1,69 +1,196 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
-	"sigs.k8s.io/yaml"
+	"gopkg.in/yaml.v3"
 )
 
+// DefaultPath — путь к конфигу, если он не задан флагом --config или CONFIG_PATH
+const DefaultPath = "config/config.yaml"
+
 type Config struct {
-	DB  DB  `yaml:"db"`
-	Log Log `yaml:"log"`
+	Server  Server  `yaml:"server"`
+	Storage Storage `yaml:"storage"`
+	DB      DB      `yaml:"db"`
+	Log     Log     `yaml:"log"`
+	Tracing Tracing `yaml:"tracing"`
+	Migrate Migrate `yaml:"migrate"`
+	Auth    Auth    `yaml:"auth"`
+	Workers Workers `yaml:"workers"`
+	// Subscriptions — правила для самих подписок
+	Subscriptions Subscriptions `yaml:"subscriptions"`
+}
+
+type Server struct {
+	Addr            string        `yaml:"addr" env:"SERVER_ADDR"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// DrainDelay — сколько отвечать not-ready перед остановкой,
+	// чтобы оркестратор успел убрать под из балансировки
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY"`
+}
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
+)
+
+type Storage struct {
+	// Driver выбирает хранилище: postgres, sqlite (файл рядом с бинарником)
+	// или memory (данные в памяти процесса, без БД)
+	Driver string `yaml:"driver" env:"STORAGE_DRIVER"`
+	// SQLitePath — файл базы для хранилища sqlite, создается при первом запуске
+	SQLitePath string `yaml:"sqlite_path" env:"STORAGE_SQLITE_PATH"`
+	// SeedFile — JSON-массив подписок (формат команды export) для начального заполнения memory
+	SeedFile string `yaml:"seed_file" env:"STORAGE_SEED_FILE"`
+}
+
+type Migrate struct {
+	// OnStartup применяет встроенные миграции перед запуском сервера
+	OnStartup bool `yaml:"on_startup" env:"MIGRATE_ON_STARTUP"`
 }
 
 type Log struct {
 	Level string `yaml:"level" env:"LOG_LEVEL"`
 }
 
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"` // none, stdout, otlp
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"` // host:port OTLP/HTTP коллектора
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
 type DB struct {
 	User     string `yaml:"user" env:"DB_USERNAME"`
 	Host     string `yaml:"host" env:"DB_HOST"`
 	Port     string `yaml:"port" env:"DB_PORT"`
 	DBName   string `yaml:"dbname" env:"DB_NAME"`
-	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
 	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE"`
+	// ReplicaDSN — необязательная реплика для чтения (URL или key=value)
+	ReplicaDSN       string        `yaml:"replica_dsn" env:"DB_REPLICA_DSN" secret:"true"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	ConnectAttempts  int           `yaml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS"`
+	ConnectBackoff   time.Duration `yaml:"connect_backoff" env:"DB_CONNECT_BACKOFF"`
+	Pool             Pool          `yaml:"pool"`
+}
+
+type Pool struct {
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_POOL_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_POOL_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_POOL_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_POOL_CONN_MAX_IDLE_TIME"`
+}
+
+type Auth struct {
+	Enabled bool `yaml:"enabled" env:"AUTH_ENABLED"`
+	// APIKeys — ключи доступа к /api/v1 в формате name:key; name попадает в access-лог как пользователь
+	APIKeys []string `yaml:"api_keys" env:"AUTH_API_KEYS" secret:"true"`
 }
 
-func LoadConfig() (Config, error) {
+type Workers struct {
+	// Enabled запускает фоновые задачи вместе с сервером
+	Enabled  bool          `yaml:"enabled" env:"WORKERS_ENABLED"`
+	Interval time.Duration `yaml:"interval" env:"WORKERS_INTERVAL"`
+	// TrialReminderBefore — за сколько до начала оплаты напоминать о конце пробного периода
+	TrialReminderBefore time.Duration `yaml:"trial_reminder_before" env:"WORKERS_TRIAL_REMINDER_BEFORE"`
+}
+
+const (
+	OverlapWarn   = "warn"
+	OverlapReject = "reject"
+)
+
+type Subscriptions struct {
+	// OverlapPolicy — что делать с подпиской, пересекающейся по периоду с другой подпиской того же
+	// пользователя на тот же сервис: warn сохраняет ее с предупреждением, reject отклоняет
+	OverlapPolicy string `yaml:"overlap_policy" env:"SUBSCRIPTIONS_OVERLAP_POLICY"`
+}
+
+// Default возвращает конфигурацию, поверх которой применяются файл и переменные окружения
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:            ":8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+			DrainDelay:      5 * time.Second,
+		},
+		Storage: Storage{Driver: StoragePostgres, SQLitePath: "subscriptions.db"},
+		DB: DB{
+			Host:             "localhost",
+			Port:             "5432",
+			SSLMode:          "disable",
+			StatementTimeout: 30 * time.Second,
+			ConnectAttempts:  5,
+			ConnectBackoff:   time.Second,
+			Pool: Pool{
+				MaxOpenConns:    25,
+				MaxIdleConns:    25,
+				ConnMaxLifetime: 30 * time.Minute,
+				ConnMaxIdleTime: 5 * time.Minute,
+			},
+		},
+		Log: Log{Level: "info"},
+		Tracing: Tracing{
+			Exporter:    "none",
+			ServiceName: "subscriptions",
+			SampleRatio: 1,
+		},
+		Workers:       Workers{Interval: time.Hour, TrialReminderBefore: 72 * time.Hour},
+		Subscriptions: Subscriptions{OverlapPolicy: OverlapWarn},
+	}
+}
+
+// LoadConfig собирает конфигурацию: значения по умолчанию, затем YAML-файл path,
+// затем переменные окружения из тегов env (включая .env). Пустой path означает
+// DefaultPath, отсутствие которого не ошибка; явно указанный файл обязан существовать.
+func LoadConfig(path string) (Config, error) {
 	// Загружаем .env файл
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	config := Default()
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath
+	}
+
 	// Читаем YAML конфиг
-	data, err := os.ReadFile("config/config.yaml")
-	if err != nil {
-		return Config{}, err
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return Config{}, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case explicit || !errors.Is(err, fs.ErrNotExist):
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	// Переопределяем значения из окружения
+	if err := applyEnv(&config); err != nil {
 		return Config{}, err
 	}
 
-	// Переопределяем значения из .env файла
-	config.DB.User = getEnv("DB_USER", config.DB.User)
-	config.DB.Host = getEnv("DB_HOST", config.DB.Host)
-	config.DB.Port = getEnv("DB_PORT", config.DB.Port)
-	config.DB.DBName = getEnv("DB_NAME", config.DB.DBName)
-	config.DB.Password = getEnv("DB_PASSWORD", config.DB.Password)
-	config.DB.SSLMode = getEnv("DB_SSLMODE", config.DB.SSLMode)
-	config.Log.Level = getEnv("LOG_LEVEL", config.Log.Level)
-
-	// Устанавливаем значения по умолчанию если пустые
-	if config.Log.Level == "" {
-		config.Log.Level = "info"
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config:\n%w", err)
 	}
 
 	return config, nil
 }
-
-// getEnv — вспомогательная функция для получения переменной окружения или значения по умолчанию
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return defaultValue
-}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redactedValue = "***"
+
+// Redacted возвращает копию конфигурации, в которой поля с тегом secret:"true" скрыты
+func (c Config) Redacted() Config {
+	redactStruct(reflect.ValueOf(&c).Elem())
+	return c
+}
+
+// Dump сериализует конфигурацию без секретов в YAML для вывода и логов
+func (c Config) Dump() (string, error) {
+	data, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func redactStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			redactStruct(field)
+			continue
+		}
+		if t.Field(i).Tag.Get("secret") != "true" || field.IsZero() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(redactedValue)
+		case reflect.Slice:
+			redacted := make([]string, field.Len())
+			for j := range redacted {
+				redacted[j] = redactedValue
+			}
+			field.Set(reflect.ValueOf(redacted))
+		}
+	}
+}
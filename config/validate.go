@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные ошибки сразу
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Addr == "" {
+		fail("server.addr is required")
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.ShutdownTimeout <= 0 {
+		fail("server read, write and shutdown timeouts must be positive")
+	}
+	if c.Server.DrainDelay < 0 {
+		fail("server.drain_delay must not be negative")
+	}
+
+	switch c.Storage.Driver {
+	case StoragePostgres:
+		errs = append(errs, c.DB.validate()...)
+	case StorageSQLite:
+		if c.Storage.SQLitePath == "" {
+			fail("storage.sqlite_path (STORAGE_SQLITE_PATH) is required for the sqlite storage")
+		}
+	case StorageMemory:
+	default:
+		fail("storage.driver (STORAGE_DRIVER) must be one of postgres, sqlite, memory, got %q", c.Storage.Driver)
+	}
+	if c.Storage.SeedFile != "" && c.Storage.Driver != StorageMemory {
+		fail("storage.seed_file is supported only by the memory storage")
+	}
+
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		fail("log.level (LOG_LEVEL) %q is not a valid level", c.Log.Level)
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		fail("tracing.exporter (TRACING_EXPORTER) must be one of none, stdout, otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio <= 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio must be in (0, 1], got %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Auth.Enabled && len(c.Auth.APIKeys) == 0 {
+		fail("auth.api_keys (AUTH_API_KEYS) is required when auth is enabled")
+	}
+	for i, entry := range c.Auth.APIKeys {
+		if name, key, ok := strings.Cut(entry, ":"); !ok || name == "" || key == "" {
+			fail("auth.api_keys[%d] must have the form name:key", i)
+		}
+	}
+
+	if c.Workers.Enabled && c.Workers.Interval <= 0 {
+		fail("workers.interval must be positive when workers are enabled")
+	}
+	if c.Workers.Enabled && c.Workers.TrialReminderBefore <= 0 {
+		fail("workers.trial_reminder_before must be positive when workers are enabled")
+	}
+
+	switch c.Subscriptions.OverlapPolicy {
+	case OverlapWarn, OverlapReject:
+	default:
+		fail("subscriptions.overlap_policy (SUBSCRIPTIONS_OVERLAP_POLICY) must be one of warn, reject, got %q", c.Subscriptions.OverlapPolicy)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate проверяет настройки подключения; они нужны только хранилищу postgres
+func (db DB) validate() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if db.Host == "" {
+		fail("db.host (DB_HOST) is required")
+	}
+	if port, err := strconv.Atoi(db.Port); err != nil || port <= 0 || port > 65535 {
+		fail("db.port (DB_PORT) must be a valid port, got %q", db.Port)
+	}
+	if db.User == "" {
+		fail("db.user (DB_USERNAME) is required")
+	}
+	if db.DBName == "" {
+		fail("db.dbname (DB_NAME) is required")
+	}
+	switch db.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		fail("db.sslmode (DB_SSLMODE) %q is not a valid sslmode", db.SSLMode)
+	}
+	if db.StatementTimeout < 0 {
+		fail("db.statement_timeout must not be negative")
+	}
+	if db.ConnectAttempts < 1 || db.ConnectBackoff <= 0 {
+		fail("db.connect_attempts must be at least 1 and db.connect_backoff positive")
+	}
+	if db.Pool.MaxOpenConns < 0 || db.Pool.MaxIdleConns < 0 {
+		fail("db.pool connection limits must not be negative")
+	}
+	if db.Pool.MaxOpenConns > 0 && db.Pool.MaxIdleConns > db.Pool.MaxOpenConns {
+		fail("db.pool.max_idle_conns (%d) must not exceed max_open_conns (%d)", db.Pool.MaxIdleConns, db.Pool.MaxOpenConns)
+	}
+
+	return errs
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Периоды бюджета
+const (
+	BudgetMonthly = "monthly"
+	BudgetYearly  = "yearly"
+)
+
+// EventBudgetExceeded — прогноз расходов за период превысил бюджет
+const EventBudgetExceeded = "budget_exceeded"
+
+// Budget — лимит расходов пользователя за месяц или календарный год: на все подписки,
+// на один сервис ServiceID или на тег Tag
+type Budget struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	Period    string     `json:"period" db:"period"`
+	Amount    int        `json:"amount" db:"amount"`
+	ServiceID *uuid.UUID `json:"service_id,omitempty" db:"service_id"`
+	Tag       *string    `json:"tag,omitempty" db:"tag"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateBudgetRequest задает бюджет; сервис указывается service_id или названием/алиасом из каталога
+type CreateBudgetRequest struct {
+	Period      string     `json:"period" binding:"required,oneof=monthly yearly"`
+	Amount      int        `json:"amount" binding:"required,min=1"`
+	ServiceID   *uuid.UUID `json:"service_id,omitempty"`
+	ServiceName *string    `json:"service_name,omitempty" binding:"excluded_with=ServiceID"`
+	Tag         *string    `json:"tag,omitempty" binding:"excluded_with=ServiceID ServiceName"`
+}
+
+// UpdateBudgetRequest меняет лимит или период; область бюджета не меняется
+type UpdateBudgetRequest struct {
+	Period *string `json:"period,omitempty" binding:"omitempty,oneof=monthly yearly"`
+	Amount *int    `json:"amount,omitempty" binding:"omitempty,min=1"`
+}
+
+// BudgetStatus сравнивает бюджет с расходами в текущем периоде: Actual — с начала периода
+// по текущий месяц включительно, Projected — за весь период с учетом уже известных изменений
+type BudgetStatus struct {
+	Budget
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	Actual      int    `json:"actual"`
+	Projected   int    `json:"projected"`
+	Remaining   int    `json:"remaining"`
+	Exceeded    bool   `json:"exceeded"`
+}
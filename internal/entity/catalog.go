@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Service — запись каталога сервисов. Подписки ссылаются на нее по service_id,
+// а название из запросов сопоставляется с каталогом по имени или алиасам.
+type Service struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Aliases      []string  `json:"aliases" db:"-"`
+	Category     string    `json:"category" db:"category"`
+	Website      string    `json:"website" db:"website"`
+	DefaultPrice *int      `json:"default_price,omitempty" db:"default_price"`
+}
+
+type CreateServiceRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	Website      string   `json:"website,omitempty" binding:"omitempty,url"`
+	DefaultPrice *int     `json:"default_price,omitempty" binding:"omitempty,min=1"`
+}
+
+// UpdateServiceRequest обновляет переданные поля; aliases заменяет список целиком,
+// default_price = 0 убирает цену по умолчанию
+type UpdateServiceRequest struct {
+	Name         *string   `json:"name,omitempty"`
+	Aliases      *[]string `json:"aliases,omitempty"`
+	Category     *string   `json:"category,omitempty"`
+	Website      *string   `json:"website,omitempty" binding:"omitempty,url"`
+	DefaultPrice *int      `json:"default_price,omitempty" binding:"omitempty,min=0"`
+}
+
+// NormalizeServiceName приводит название к ключу поиска в каталоге:
+// "Netflix", "netflix" и " Netflix " дают один и тот же ключ
+func NormalizeServiceName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Форматы дат подписки: полная дата ISO 8601 или месяц, как до появления дат с точностью до дня
+const (
+	DateLayout  = "2006-01-02"
+	MonthLayout = "01-2006"
+)
+
+// ParseDate разбирает дату начала в формате YYYY-MM-DD или MM-YYYY; месяц означает его первое число
+func ParseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(MonthLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or MM-YYYY, got %q", value)
+	}
+	return t, nil
+}
+
+// ParseEndDate разбирает дату окончания так же, как ParseDate, но месяц означает его последний день:
+// подписка с end_date MM-YYYY действует весь этот месяц
+func ParseEndDate(value string) (time.Time, error) {
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(MonthLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or MM-YYYY, got %q", value)
+	}
+	return MonthEnd(t), nil
+}
+
+// MonthEnd возвращает последнее число месяца t в UTC
+func MonthEnd(t time.Time) time.Time {
+	return MonthStart(t).AddDate(0, 1, -1)
+}
+
+// DaysIn возвращает число дней в месяце t
+func DaysIn(t time.Time) int {
+	return MonthEnd(t).Day()
+}
+
+// ActiveDays возвращает, сколько дней месяца month подписка действует: от start_date до end_date
+// включительно, кроме дней на паузе
+func (s *Subscription) ActiveDays(month time.Time) int {
+	first, last := MonthStart(month), MonthEnd(month)
+	if s.StartDate.After(first) {
+		first = s.StartDate
+	}
+	if s.EndDate != nil && s.EndDate.Before(last) {
+		last = *s.EndDate
+	}
+	if first.After(last) {
+		return 0
+	}
+	return daysBetween(first, last) - s.pausedDays(first, last)
+}
+
+// ChargeDay возвращает день списания: BillingDay или день начала подписки
+func (s *Subscription) ChargeDay() int {
+	if s.BillingDay != nil {
+		return *s.BillingDay
+	}
+	return s.StartDate.Day()
+}
+
+// NextChargeAt возвращает ближайшую дату списания не раньше дня now. В коротких месяцах списание
+// переносится на последний день; пробные месяцы, дни до начала подписки и дни на паузе пропускаются.
+// nil — подписка больше не оплачивается.
+func (s *Subscription) NextChargeAt(now time.Time) *time.Time {
+	today := DateOf(now)
+	month := MonthStart(today)
+	if start := MonthStart(s.StartDate); start.After(month) {
+		month = start
+	}
+
+	for ; ; month = month.AddDate(0, 1, 0) {
+		if s.EndDate != nil && month.After(*s.EndDate) {
+			return nil
+		}
+		if s.InTrial(month) {
+			continue
+		}
+		charge := month.AddDate(0, 0, min(s.ChargeDay(), DaysIn(month))-1)
+		if charge.Before(today) || charge.Before(s.StartDate) {
+			continue
+		}
+		if s.EndDate != nil && charge.After(*s.EndDate) {
+			return nil
+		}
+		if s.PausedOn(charge) {
+			if open := s.OpenPause(); open != nil && !charge.Before(open.StartDate) {
+				return nil
+			}
+			continue
+		}
+		return &charge
+	}
+}
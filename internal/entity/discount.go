@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Discount — скидка на подписку: процент Percent или фиксированная сумма Amount
+// в месяцах от ValidFrom до ValidTo включительно; без ValidTo скидка бессрочная
+type Discount struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Percent   *int       `json:"percent,omitempty" db:"percent"`
+	Amount    *int       `json:"amount,omitempty" db:"amount"`
+	ValidFrom time.Time  `json:"valid_from" db:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to,omitempty" db:"valid_to"`
+}
+
+// DiscountRequest задает скидку: percent или amount, с месяца valid_from (MM-YYYY, по умолчанию
+// start_date подписки) до valid_to включительно или на months месяцев; без обоих — бессрочно
+type DiscountRequest struct {
+	Name      string  `json:"name" binding:"max=100"`
+	Percent   *int    `json:"percent,omitempty" binding:"omitempty,min=1,max=100"`
+	Amount    *int    `json:"amount,omitempty" binding:"omitempty,min=1"`
+	ValidFrom *string `json:"valid_from,omitempty"`
+	ValidTo   *string `json:"valid_to,omitempty"`
+	Months    *int    `json:"months,omitempty" binding:"omitempty,min=1,excluded_with=ValidTo"`
+}
+
+// ActiveAt сообщает, действует ли скидка в месяце month
+func (d Discount) ActiveAt(month time.Time) bool {
+	return !month.Before(d.ValidFrom) && (d.ValidTo == nil || !month.After(*d.ValidTo))
+}
+
+// EffectivePriceAt возвращает цену месяца month после скидок, действующих в этом месяце:
+// сначала процентные (скидка округляется вниз), затем фиксированные; цена не бывает меньше нуля
+func (s *Subscription) EffectivePriceAt(month time.Time) int {
+	price := s.PriceAt(month)
+	for _, d := range s.Discounts {
+		if d.Percent != nil && d.ActiveAt(month) {
+			price -= price * *d.Percent / 100
+		}
+	}
+	for _, d := range s.Discounts {
+		if d.Amount != nil && d.ActiveAt(month) {
+			price -= min(*d.Amount, price)
+		}
+	}
+	return price
+}
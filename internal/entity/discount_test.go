@@ -0,0 +1,83 @@
+package entity
+
+import "testing"
+
+func TestEffectivePriceAt(t *testing.T) {
+	percent := func(p int, from string, to *string) Discount {
+		d := Discount{Percent: &p, ValidFrom: day(from)}
+		if to != nil {
+			d.ValidTo = dayPtr(*to)
+		}
+		return d
+	}
+	amount := func(a int, from string) Discount { return Discount{Amount: &a, ValidFrom: day(from)} }
+	until := func(month string) *string { return &month }
+
+	tests := []struct {
+		name      string
+		discounts []Discount
+		month     string
+		want      int
+	}{
+		{name: "без скидок", month: "2026-03-01", want: 999},
+		{name: "процент округляется вниз", discounts: []Discount{percent(15, "2026-01-01", nil)}, month: "2026-03-01", want: 850},
+		{
+			name:      "проценты применяются по очереди",
+			discounts: []Discount{percent(50, "2026-01-01", nil), percent(10, "2026-02-01", nil)},
+			month:     "2026-03-01",
+			// 999 - 499 = 500, затем 500 - 50 = 450
+			want: 450,
+		},
+		{
+			name:      "фиксированная после процентной",
+			discounts: []Discount{amount(100, "2026-01-01"), percent(50, "2026-01-01", nil)},
+			month:     "2026-03-01",
+			want:      400,
+		},
+		{name: "цена не меньше нуля", discounts: []Discount{amount(600, "2026-01-01"), amount(600, "2026-01-01")}, month: "2026-03-01", want: 0},
+		{name: "до начала скидки", discounts: []Discount{percent(50, "2026-04-01", nil)}, month: "2026-03-01", want: 999},
+		{name: "последний месяц скидки", discounts: []Discount{percent(50, "2026-01-01", until("2026-03-01"))}, month: "2026-03-01", want: 500},
+		{name: "после конца скидки", discounts: []Discount{percent(50, "2026-01-01", until("2026-02-01"))}, month: "2026-03-01", want: 999},
+		{name: "100% скидка", discounts: []Discount{percent(100, "2026-01-01", nil), amount(10, "2026-01-01")}, month: "2026-03-01", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{
+				StartDate: day("2026-01-01"),
+				Prices:    []PricePeriod{{EffectiveFrom: day("2026-01-01"), Price: 999}},
+				Discounts: tt.discounts,
+			}
+			if got := sub.EffectivePriceAt(day(tt.month)); got != tt.want {
+				t.Errorf("EffectivePriceAt(%s) = %d, want %d", tt.month, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceAt(t *testing.T) {
+	sub := Subscription{Prices: []PricePeriod{
+		{EffectiveFrom: day("2026-03-01"), Price: 100},
+		{EffectiveFrom: day("2026-06-01"), Price: 150},
+	}}
+
+	tests := []struct {
+		month string
+		want  int
+	}{
+		// До первого периода действует его цена
+		{month: "2026-01-01", want: 100},
+		{month: "2026-03-01", want: 100},
+		{month: "2026-05-01", want: 100},
+		{month: "2026-06-01", want: 150},
+		{month: "2027-01-01", want: 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.month, func(t *testing.T) {
+			if got := sub.PriceAt(day(tt.month)); got != tt.want {
+				t.Errorf("PriceAt(%s) = %d, want %d", tt.month, got, tt.want)
+			}
+		})
+	}
+}
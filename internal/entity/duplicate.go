@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DuplicateGroup — подписки одного плательщика на один сервис с пересекающимися периодами
+type DuplicateGroup struct {
+	UserID        uuid.UUID       `json:"user_id"`
+	ServiceID     uuid.UUID       `json:"service_id"`
+	ServiceName   string          `json:"service_name"`
+	Subscriptions []*Subscription `json:"subscriptions"`
+}
+
+// MergeRequest — подписки, которые вливаются в подписку из пути и удаляются
+type MergeRequest struct {
+	DuplicateIDs []uuid.UUID `json:"duplicate_ids" binding:"required,min=1"`
+}
+
+// OverlapsPeriod сообщает, что подписка активна хотя бы в одном месяце от start до end;
+// end = nil — период без конца
+func (s *Subscription) OverlapsPeriod(start time.Time, end *time.Time) bool {
+	if end != nil && s.StartDate.After(*end) {
+		return false
+	}
+	return s.EndDate == nil || !s.EndDate.Before(start)
+}
+
+// Duplicates сообщает, что подписки оплачивает один пользователь за один сервис в пересекающиеся периоды
+func (s *Subscription) Duplicates(other *Subscription) bool {
+	return s.ID != other.ID && s.UserID == other.UserID && s.ServiceID == other.ServiceID &&
+		s.OverlapsPeriod(other.StartDate, other.EndDate)
+}
@@ -1,43 +1,188 @@
 package entity
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Subscription — подписка, которую оплачивает UserID. Price — цена по прайсу в текущем месяце,
+// EffectivePrice — она же после скидок Discounts; история цен и запланированные изменения —
+// в Prices, по возрастанию EffectiveFrom. Месяцы до TrialEndDate включительно бесплатны.
+// StartDate и EndDate — первый и последний день подписки. Price и EffectivePrice вычисляются
+// на текущий месяц, Status и NextChargeDate — на сегодня или на день as_of из запроса.
 type Subscription struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	ServiceName string     `json:"service_name" db:"service_name"`
-	Price       int        `json:"price" db:"price"`
-	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
-	StartDate   time.Time  `json:"start_date" db:"start_date"`
-	EndDate     *time.Time `json:"end_date,omitempty" db:"end_date"`
+	ID             uuid.UUID  `json:"id" db:"id"`
+	ServiceID      uuid.UUID  `json:"service_id" db:"service_id"`
+	ServiceName    string     `json:"service_name" db:"service_name"`
+	Price          int        `json:"price" db:"-"`
+	EffectivePrice int        `json:"effective_price" db:"-"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	StartDate      time.Time  `json:"start_date" db:"start_date"`
+	EndDate        *time.Time `json:"end_date,omitempty" db:"end_date"`
+	TrialEndDate   *time.Time `json:"trial_end_date,omitempty" db:"trial_end_date"`
+	// BillingDay — день месяца, в который списывается оплата; nil — день начала подписки
+	BillingDay     *int          `json:"billing_day,omitempty" db:"billing_day"`
+	NextChargeDate *time.Time    `json:"next_charge_date,omitempty" db:"-"`
+	Status         string        `json:"status" db:"-"`
+	Tags           []string      `json:"tags" db:"-"`
+	Prices         []PricePeriod `json:"prices" db:"-"`
+	Discounts      []Discount    `json:"discounts" db:"-"`
+	// Participants делят стоимость с плательщиком UserID; пустой список — платит и пользуется один
+	Participants []Participant `json:"participants" db:"-"`
+	// Pauses — периоды приостановки; дни на паузе не оплачиваются
+	Pauses []Pause `json:"pauses" db:"-"`
+	// CancelReason, CancelComment и CancelledAt заполняет отмена; последний день подписки — EndDate
+	CancelReason  *string    `json:"cancel_reason,omitempty" db:"cancel_reason"`
+	CancelComment *string    `json:"cancel_comment,omitempty" db:"cancel_comment"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	// Warnings — предупреждения при создании, например о пересечении с другой подпиской; не хранятся
+	Warnings []string `json:"warnings,omitempty" db:"-"`
+	// Exclusive — подписка сохранена при политике reject: ограничение в Postgres не даст ей пересечься
+	// с другой такой же подпиской пользователя на тот же сервис. Заполняет сервис
+	Exclusive bool `json:"-" db:"exclusive"`
 }
 
+// Evaluate заполняет вычисляемые поля на момент now
+func (s *Subscription) Evaluate(now time.Time) {
+	month := MonthStart(now)
+	s.Price = s.PriceAt(month)
+	s.EffectivePrice = s.EffectivePriceAt(month)
+	s.Status = s.StatusAt(now)
+	s.NextChargeDate = s.NextChargeAt(now)
+}
+
+// CreateSubscriptionRequest указывает сервис по service_id или по названию/алиасу из каталога;
+// неизвестное название добавляется в каталог. Без price берется цена сервиса по умолчанию.
 type CreateSubscriptionRequest struct {
-	ServiceName string    `json:"service_name" binding:"required"`
-	Price       int       `json:"price" binding:"required,min=1"`
-	UserID      uuid.UUID `json:"user_id" binding:"required"`
-	StartDate   string    `json:"start_date" binding:"required"`
-	EndDate     *string   `json:"end_date,omitempty"`
+	ServiceID   *uuid.UUID `json:"service_id,omitempty"`
+	ServiceName string     `json:"service_name" binding:"required_without=ServiceID"`
+	Price       int        `json:"price" binding:"omitempty,min=1"`
+	UserID      uuid.UUID  `json:"user_id" binding:"required"`
+	// StartDate и EndDate — дата (YYYY-MM-DD) или месяц (MM-YYYY): с первого или по последний день месяца
+	StartDate string  `json:"start_date" binding:"required"`
+	EndDate   *string `json:"end_date,omitempty"`
+	// BillingDay — день месяца для списания; по умолчанию день начала подписки
+	BillingDay *int `json:"billing_day,omitempty" binding:"omitempty,min=1,max=31"`
+	// TrialEndDate — последний бесплатный месяц (MM-YYYY или дата в нем); TrialMonths — то же числом бесплатных месяцев с начала
+	TrialEndDate *string           `json:"trial_end_date,omitempty"`
+	TrialMonths  *int              `json:"trial_months,omitempty" binding:"omitempty,min=1,excluded_with=TrialEndDate"`
+	Tags         []string          `json:"tags,omitempty"`
+	Discounts    []DiscountRequest `json:"discounts,omitempty" binding:"omitempty,dive"`
+	// Participants — с кем делится стоимость; сам плательщик указывается, только если у него есть вес или сумма
+	Participants []Participant `json:"participants,omitempty" binding:"omitempty,dive"`
 }
 
+// UpdateSubscriptionRequest обновляет переданные поля; tags и participants заменяют список целиком.
+// Новая price действует с текущего месяца, прошлые месяцы сохраняют прежнюю цену.
 type UpdateSubscriptionRequest struct {
-	ServiceName *string `json:"service_name,omitempty"`
-	Price       *int    `json:"price,omitempty"`
-	StartDate   *string `json:"start_date,omitempty"`
-	EndDate     *string `json:"end_date,omitempty"`
+	ServiceID   *uuid.UUID `json:"service_id,omitempty"`
+	ServiceName *string    `json:"service_name,omitempty"`
+	Price       *int       `json:"price,omitempty"`
+	// PriceFrom — месяц, с которого действует Price; заполняет сервис
+	PriceFrom time.Time `json:"-"`
+	StartDate *string   `json:"start_date,omitempty"`
+	EndDate   *string   `json:"end_date,omitempty"`
+	// BillingDay меняет день списания
+	BillingDay *int `json:"billing_day,omitempty" binding:"omitempty,min=1,max=31"`
+	// TrialEndDate задает последний бесплатный месяц; пустая строка убирает пробный период
+	TrialEndDate *string `json:"trial_end_date,omitempty"`
+	// TrialEnd — месяц из TrialEndDate или TrialMonths, nil — без пробного периода; заполняет сервис
+	TrialEnd *time.Time `json:"-"`
+	// TrialMonths задает пробный период числом бесплатных месяцев от start_date
+	TrialMonths *int      `json:"trial_months,omitempty" binding:"omitempty,min=1,excluded_with=TrialEndDate"`
+	Tags        *[]string `json:"tags,omitempty"`
+	// Participants заменяет участников; пустой список делает подписку личной
+	Participants *[]Participant `json:"participants,omitempty" binding:"omitempty,dive"`
+	// Exclusive заполняет сервис при смене сервиса или дат, как Subscription.Exclusive
+	Exclusive *bool `json:"-"`
+}
+
+// ListSubscriptionsRequest — пагинация и фильтры списка подписок
+type ListSubscriptionsRequest struct {
+	Limit  int
+	Offset int
+	// UserID оставляет подписки одного пользователя
+	UserID *uuid.UUID
+	// Tags оставляет подписки, у которых есть все перечисленные теги
+	Tags []string
+	// Statuses оставляет подписки с одним из статусов на день AsOf
+	Statuses []string
+	// AsOf — день (YYYY-MM-DD или MM-YYYY), на который вычисляются статус и цены; по умолчанию сегодня
+	AsOf *string
+	// StatusDate — разобранный AsOf, заполняет сервис. С Statuses List отбирает подписки,
+	// даты которых допускают один из статусов (Subscription.MayHaveStatus)
+	StatusDate time.Time
+	// After начинает страницу сразу после этой позиции вместо Offset: обход всех подписок
+	// не пропускает и не повторяет их, даже если подписки добавляют или удаляют во время обхода
+	After *ListCursor
 }
 
+// ListCursor — позиция в списке подписок, упорядоченном по start_date, id
+type ListCursor struct {
+	StartDate time.Time
+	ID        uuid.UUID
+}
+
+// SubscriptionSummaryRequest — фильтры summary. user_id разбирает хендлер:
+// gin не умеет привязывать uuid.UUID из query.
 type SubscriptionSummaryRequest struct {
-	UserID      *uuid.UUID `form:"user_id"`
+	UserID      *uuid.UUID `form:"-"`
 	ServiceName *string    `form:"service_name"`
 	StartPeriod *string    `form:"start_period"`
 	EndPeriod   *string    `form:"end_period"`
+	Tags        []string   `form:"tag"`
+	// Prorate считает неполные первый и последний месяцы подписки пропорционально числу дней
+	Prorate bool `form:"prorate"`
+	// Statuses оставляет подписки с одним из статусов на день AsOf (по умолчанию сегодня)
+	Statuses []string `form:"status"`
+	AsOf     *string  `form:"as_of"`
+	// Shared оставляет только совместные (true) или только личные (false) подписки; заполняет сервис
+	Shared *bool `form:"-"`
+}
+
+// SummaryWindow — месяцы From..To, за которые считается стоимость; Prorate — с долей дней неполных месяцев
+type SummaryWindow struct {
+	From, To time.Time
+	Prorate  bool
+}
+
+// Window возвращает месяцы summary: от start_period до end_period, а без периода — только месяц now
+// по текущей цене, без пропорционального расчета
+func (r *SubscriptionSummaryRequest) Window(now time.Time) (SummaryWindow, error) {
+	if r.StartPeriod == nil || r.EndPeriod == nil {
+		month := MonthStart(now)
+		return SummaryWindow{From: month, To: month}, nil
+	}
+	from, err := time.Parse(MonthLayout, *r.StartPeriod)
+	if err != nil {
+		return SummaryWindow{}, fmt.Errorf("invalid start_period format: %w", err)
+	}
+	to, err := time.Parse(MonthLayout, *r.EndPeriod)
+	if err != nil {
+		return SummaryWindow{}, fmt.Errorf("invalid end_period format: %w", err)
+	}
+	return SummaryWindow{From: from, To: to, Prorate: r.Prorate}, nil
+}
+
+// AsOfDay возвращает день, на который проверяются Statuses; формат проверяет сервис
+func (r *SubscriptionSummaryRequest) AsOfDay(now time.Time) time.Time {
+	if r.AsOf != nil {
+		if day, err := ParseDate(*r.AsOf); err == nil {
+			return day
+		}
+	}
+	return DateOf(now)
 }
 
 type SubscriptionSummary struct {
 	TotalCost int `json:"total_cost"`
 	Count     int `json:"count"`
 }
+
+type ServiceStats struct {
+	ServiceName  string `json:"service_name" db:"service_name"`
+	ActiveCount  int    `json:"active_count" db:"active_count"`
+	MonthlySpend int    `json:"monthly_spend" db:"monthly_spend"`
+}
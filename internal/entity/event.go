@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventTrialEnding — напоминание о скором окончании пробного периода
+const EventTrialEnding = "trial_ending"
+
+// Event — событие для пользователя, которое создают фоновые задачи
+type Event struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Type           string     `json:"type" db:"type"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" db:"subscription_id"`
+	Message        string     `json:"message" db:"message"`
+	// DedupKey не дает создать одно и то же событие повторно; пустой — без проверки
+	DedupKey  string    `json:"-" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ForecastRequest — параметры прогноза. user_id и cancel разбирает хендлер:
+// gin не умеет привязывать uuid.UUID из query.
+type ForecastRequest struct {
+	UserID      *uuid.UUID `form:"-"`
+	ServiceName *string    `form:"service_name"`
+	Months      int        `form:"months" binding:"omitempty,min=1,max=36"`
+	Tags        []string   `form:"tag"`
+	// Cancel — подписки, которые считаются отмененными с первого месяца прогноза
+	Cancel []uuid.UUID `form:"-"`
+}
+
+// Forecast — прогноз расходов по месяцам; Savings — сколько сэкономит отмена подписок из cancel
+type Forecast struct {
+	StartPeriod string           `json:"start_period"`
+	EndPeriod   string           `json:"end_period"`
+	TotalCost   int              `json:"total_cost"`
+	Savings     int              `json:"savings"`
+	Months      []*ForecastMonth `json:"months"`
+}
+
+type ForecastMonth struct {
+	Month     string         `json:"month"`
+	TotalCost int            `json:"total_cost"`
+	Savings   int            `json:"savings"`
+	Users     []*UserCost    `json:"users"`
+	Services  []*ServiceCost `json:"services"`
+}
+
+// UserCost — доля пользователя в расходах месяца
+type UserCost struct {
+	UserID uuid.UUID `json:"user_id"`
+	Cost   int       `json:"cost"`
+}
+
+type ServiceCost struct {
+	ServiceName string `json:"service_name"`
+	Cost        int    `json:"cost"`
+}
+
+// MonthSpend — стоимость подписок плательщика UserID на сервис в месяце Month;
+// Cancelled — подписки, отмененные в прогнозе
+type MonthSpend struct {
+	Month       time.Time
+	ServiceName string
+	UserID      uuid.UUID
+	Cancelled   bool
+	Cost        int
+}
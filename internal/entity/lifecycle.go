@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Причины отмены подписки
+const (
+	CancelTooExpensive    = "too_expensive"
+	CancelNotUsing        = "not_using"
+	CancelSwitched        = "switched_service"
+	CancelTechnicalIssues = "technical_issues"
+	CancelOther           = "other"
+)
+
+// Pause — приостановка подписки с StartDate по EndDate включительно; EndDate = nil — подписка еще на паузе
+type Pause struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	StartDate time.Time  `json:"start_date" db:"start_date"`
+	EndDate   *time.Time `json:"end_date,omitempty" db:"end_date"`
+}
+
+// CancelRequest отменяет подписку: effective_date становится последним днем подписки (по умолчанию сегодня)
+type CancelRequest struct {
+	EffectiveDate *string `json:"effective_date,omitempty"`
+	Reason        string  `json:"reason" binding:"required,oneof=too_expensive not_using switched_service technical_issues other"`
+	Comment       *string `json:"comment,omitempty" binding:"omitempty,max=1000"`
+}
+
+// LifecycleRequest — дата, с которой подписка встает на паузу или возобновляется (по умолчанию сегодня)
+type LifecycleRequest struct {
+	EffectiveDate *string `json:"effective_date,omitempty"`
+}
+
+// CancellationStats — отмены с одной причиной: сколько подписок и сколько они стоили в последний месяц
+type CancellationStats struct {
+	Reason         string `json:"reason"`
+	Count          int    `json:"count"`
+	MonthlySavings int    `json:"monthly_savings"`
+}
+
+// PausedOn сообщает, что день day приходится на паузу
+func (s *Subscription) PausedOn(day time.Time) bool {
+	for _, p := range s.Pauses {
+		if !day.Before(p.StartDate) && (p.EndDate == nil || !day.After(*p.EndDate)) {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenPause возвращает паузу без даты окончания, если подписка на паузе
+func (s *Subscription) OpenPause() *Pause {
+	for i := range s.Pauses {
+		if s.Pauses[i].EndDate == nil {
+			return &s.Pauses[i]
+		}
+	}
+	return nil
+}
+
+// pausedDays возвращает, сколько дней от first до last включительно приходится на паузы
+func (s *Subscription) pausedDays(first, last time.Time) int {
+	days := 0
+	for _, p := range s.Pauses {
+		from, to := p.StartDate, last
+		if p.EndDate != nil && p.EndDate.Before(to) {
+			to = *p.EndDate
+		}
+		if from.Before(first) {
+			from = first
+		}
+		if !from.After(to) {
+			days += daysBetween(from, to)
+		}
+	}
+	return days
+}
+
+// daysBetween возвращает число дней от first до last включительно
+func daysBetween(first, last time.Time) int {
+	return int(last.Sub(first).Hours()/24) + 1
+}
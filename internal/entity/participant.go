@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+// Participant — пользователь, который делит стоимость подписки с плательщиком (Subscription.UserID).
+// Задается либо вес (доля от остатка после фиксированных сумм), либо фиксированная сумма в месяц.
+type Participant struct {
+	UserID uuid.UUID `json:"user_id" db:"user_id" binding:"required"`
+	Weight *int      `json:"weight,omitempty" db:"weight" binding:"omitempty,min=1"`
+	Amount *int      `json:"amount,omitempty" db:"amount" binding:"omitempty,min=0"`
+}
+
+// Debt — сколько участник должен плательщику за период
+type Debt struct {
+	FromUserID uuid.UUID `json:"from_user_id" db:"from_user_id"`
+	ToUserID   uuid.UUID `json:"to_user_id" db:"to_user_id"`
+	Amount     int       `json:"amount" db:"amount"`
+}
+
+// Shares делит цену price (цену подписки в каком-то месяце) между плательщиком и участниками.
+// Сначала вычитаются фиксированные суммы, остаток делится по весам с округлением вниз;
+// все, что не распределено (остаток без весов, копейки от округления), приходится на плательщика.
+// Сумма долей всегда равна цене.
+func (s *Subscription) Shares(price int) map[uuid.UUID]int {
+	shares := map[uuid.UUID]int{s.UserID: 0}
+
+	remainder := price
+	totalWeight := 0
+	for _, p := range s.Participants {
+		if p.Amount != nil {
+			amount := min(*p.Amount, remainder)
+			shares[p.UserID] += amount
+			remainder -= amount
+		}
+		if p.Weight != nil {
+			totalWeight += *p.Weight
+		}
+	}
+
+	distributed := 0
+	if totalWeight > 0 {
+		for _, p := range s.Participants {
+			if p.Weight != nil {
+				share := remainder * *p.Weight / totalWeight
+				shares[p.UserID] += share
+				distributed += share
+			}
+		}
+	}
+	shares[s.UserID] += remainder - distributed
+
+	return shares
+}
+
+// ShareOf возвращает долю пользователя в цене price и участвует ли он в подписке
+func (s *Subscription) ShareOf(userID uuid.UUID, price int) (int, bool) {
+	share, ok := s.Shares(price)[userID]
+	return share, ok
+}
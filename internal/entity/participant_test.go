@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestShares(t *testing.T) {
+	payer := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	bob := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	carol := uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+	weight := func(userID uuid.UUID, w int) Participant { return Participant{UserID: userID, Weight: &w} }
+	amount := func(userID uuid.UUID, a int) Participant { return Participant{UserID: userID, Amount: &a} }
+
+	tests := []struct {
+		name         string
+		participants []Participant
+		price        int
+		want         map[uuid.UUID]int
+	}{
+		{name: "без участников", price: 300, want: map[uuid.UUID]int{payer: 300}},
+		{
+			name:         "поровну с плательщиком",
+			participants: []Participant{weight(payer, 1), weight(bob, 1)},
+			price:        300,
+			want:         map[uuid.UUID]int{payer: 150, bob: 150},
+		},
+		{
+			name:         "копейки от округления — плательщику",
+			participants: []Participant{weight(payer, 1), weight(bob, 1), weight(carol, 1)},
+			price:        100,
+			want:         map[uuid.UUID]int{payer: 34, bob: 33, carol: 33},
+		},
+		{
+			name:         "плательщик без веса не платит",
+			participants: []Participant{weight(bob, 1), weight(carol, 2)},
+			price:        300,
+			want:         map[uuid.UUID]int{payer: 0, bob: 100, carol: 200},
+		},
+		{
+			name:         "фиксированная сумма, остаток плательщику",
+			participants: []Participant{amount(bob, 50)},
+			price:        300,
+			want:         map[uuid.UUID]int{payer: 250, bob: 50},
+		},
+		{
+			name:         "сначала суммы, затем веса",
+			participants: []Participant{amount(bob, 100), weight(payer, 1), weight(carol, 3)},
+			price:        300,
+			want:         map[uuid.UUID]int{payer: 50, bob: 100, carol: 150},
+		},
+		{
+			name:         "сумма больше цены после скидки",
+			participants: []Participant{amount(bob, 100), amount(carol, 100)},
+			price:        150,
+			want:         map[uuid.UUID]int{payer: 0, bob: 100, carol: 50},
+		},
+		{
+			name:         "нулевая цена",
+			participants: []Participant{weight(payer, 1), amount(bob, 100)},
+			price:        0,
+			want:         map[uuid.UUID]int{payer: 0, bob: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Subscription{UserID: payer, Participants: tt.participants}
+			got := s.Shares(tt.price)
+
+			total := 0
+			for _, share := range got {
+				total += share
+			}
+			if total != tt.price {
+				t.Errorf("shares sum to %d, want %d", total, tt.price)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Shares(%d) = %v, want %v", tt.price, got, tt.want)
+			}
+			for userID, want := range tt.want {
+				if got[userID] != want {
+					t.Errorf("share of %s = %d, want %d", userID, got[userID], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+)
+
+// PricePeriod — цена подписки, действующая с месяца EffectiveFrom до следующего периода
+type PricePeriod struct {
+	EffectiveFrom time.Time `json:"effective_from" db:"effective_from"`
+	Price         int       `json:"price" db:"price"`
+}
+
+// SchedulePriceRequest планирует новую цену с месяца effective_from (MM-YYYY)
+type SchedulePriceRequest struct {
+	Price         int    `json:"price" binding:"required,min=1"`
+	EffectiveFrom string `json:"effective_from" binding:"required"`
+}
+
+// MonthStart возвращает первое число месяца t в UTC — так хранятся месяцы подписок
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// PriceAt возвращает цену, действовавшую в месяце month. До первого периода действует его цена;
+// без истории цен — Price.
+func (s *Subscription) PriceAt(month time.Time) int {
+	if len(s.Prices) == 0 {
+		return s.Price
+	}
+
+	price := s.Prices[0].Price
+	for _, p := range s.Prices {
+		if p.EffectiveFrom.After(month) {
+			break
+		}
+		price = p.Price
+	}
+	return price
+}
+
+// ChargeAt возвращает стоимость месяца month после скидок и то, оплачивается ли он: месяцы вне
+// подписки, пробные и целиком на паузе не оплачиваются. С prorate цена неполного месяца
+// округляется до доли дней, в которые подписка действует и не стоит на паузе.
+func (s *Subscription) ChargeAt(month time.Time, prorate bool) (int, bool) {
+	days := s.ActiveDays(month)
+	if days == 0 || s.InTrial(month) {
+		return 0, false
+	}
+
+	price := s.EffectivePriceAt(month)
+	if prorate {
+		total := DaysIn(month)
+		price = (price*days + total/2) / total
+	}
+	return price, true
+}
+
+// Charges вызывает fn для каждого оплачиваемого месяца окна со стоимостью этого месяца (см. ChargeAt)
+func (w SummaryWindow) Charges(s *Subscription, fn func(month time.Time, price int)) {
+	month := MonthStart(s.StartDate)
+	if month.Before(w.From) {
+		month = w.From
+	}
+	for ; !month.After(w.To); month = month.AddDate(0, 1, 0) {
+		if s.EndDate != nil && month.After(*s.EndDate) {
+			break
+		}
+		if price, ok := s.ChargeAt(month, w.Prorate); ok {
+			fn(month, price)
+		}
+	}
+}
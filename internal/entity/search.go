@@ -0,0 +1,20 @@
+package entity
+
+import "github.com/google/uuid"
+
+// SearchRequest ищет подписки по названию сервиса без учета регистра и с опечатками.
+// user_id разбирает хендлер, как в SubscriptionSummaryRequest.
+type SearchRequest struct {
+	Query  string     `form:"q" binding:"required,max=100"`
+	UserID *uuid.UUID `form:"-"`
+	Limit  int        `form:"limit"`
+	Offset int        `form:"offset"`
+}
+
+// SearchResult — найденная подписка. Score — похожесть названия сервиса на запрос от 0 до 1,
+// Highlight — название, экранированное для HTML, с совпавшими фрагментами в <mark>.
+type SearchResult struct {
+	Subscription *Subscription `json:"subscription"`
+	Score        float64       `json:"score"`
+	Highlight    string        `json:"highlight"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Статусы подписки на день
+const (
+	StatusScheduled  = "scheduled"
+	StatusTrialing   = "trialing"
+	StatusActive     = "active"
+	StatusPaused     = "paused"
+	StatusEndingSoon = "ending_soon"
+	StatusExpired    = "expired"
+)
+
+// Statuses — все статусы в порядке жизненного цикла подписки
+var Statuses = []string{StatusScheduled, StatusTrialing, StatusActive, StatusPaused, StatusEndingSoon, StatusExpired}
+
+// EndingSoonDays — за сколько дней до end_date подписка считается заканчивающейся
+const EndingSoonDays = 30
+
+// IsStatus сообщает, что status — один из Statuses
+func IsStatus(status string) bool {
+	return slices.Contains(Statuses, status)
+}
+
+// DateOf возвращает день t в UTC без времени
+func DateOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// StatusAt возвращает статус подписки в день now. Пауза важнее пробного периода,
+// а пробный период — близкого окончания.
+func (s *Subscription) StatusAt(now time.Time) string {
+	day := DateOf(now)
+	switch {
+	case day.Before(s.StartDate):
+		return StatusScheduled
+	case s.EndDate != nil && day.After(*s.EndDate):
+		return StatusExpired
+	case s.PausedOn(day):
+		return StatusPaused
+	case s.InTrial(MonthStart(day)):
+		return StatusTrialing
+	case s.EndDate != nil && !s.EndDate.After(day.AddDate(0, 0, EndingSoonDays)):
+		return StatusEndingSoon
+	default:
+		return StatusActive
+	}
+}
+
+// MayHaveStatus сообщает, что по датам подписки на день now возможен статус status: без пауз
+// и старшинства статусов. Так же отбирает подписки хранилище; точный статус дает StatusAt.
+func (s *Subscription) MayHaveStatus(status string, now time.Time) bool {
+	day := DateOf(now)
+	current := !day.Before(s.StartDate) && (s.EndDate == nil || !day.After(*s.EndDate))
+	switch status {
+	case StatusScheduled:
+		return day.Before(s.StartDate)
+	case StatusExpired:
+		return s.EndDate != nil && day.After(*s.EndDate)
+	case StatusTrialing:
+		return current && s.InTrial(MonthStart(day))
+	case StatusEndingSoon:
+		return current && s.EndDate != nil && !s.EndDate.After(day.AddDate(0, 0, EndingSoonDays))
+	default:
+		return current
+	}
+}
+
+// StatusByDates сообщает, что MayHaveStatus для status совпадает со StatusAt:
+// scheduled и expired старше остальных статусов и зависят только от дат
+func StatusByDates(status string) bool {
+	return status == StatusScheduled || status == StatusExpired
+}
@@ -0,0 +1,145 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func dayPtr(value string) *time.Time {
+	t := day(value)
+	return &t
+}
+
+func TestStatusAt(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  Subscription
+		now  string
+		want string
+	}{
+		{
+			name: "до начала",
+			sub:  Subscription{StartDate: day("2026-03-10")},
+			now:  "2026-03-09",
+			want: StatusScheduled,
+		},
+		{
+			name: "в день начала",
+			sub:  Subscription{StartDate: day("2026-03-10")},
+			now:  "2026-03-10",
+			want: StatusActive,
+		},
+		{
+			name: "после окончания",
+			sub:  Subscription{StartDate: day("2026-01-01"), EndDate: dayPtr("2026-03-31")},
+			now:  "2026-04-01",
+			want: StatusExpired,
+		},
+		{
+			name: "в последний день",
+			sub:  Subscription{StartDate: day("2026-01-01"), EndDate: dayPtr("2026-03-31")},
+			now:  "2026-03-31",
+			want: StatusEndingSoon,
+		},
+		{
+			name: "окончание ровно через EndingSoonDays",
+			sub:  Subscription{StartDate: day("2026-01-01"), EndDate: dayPtr("2026-03-31")},
+			now:  "2026-03-01",
+			want: StatusEndingSoon,
+		},
+		{
+			name: "окончание дальше EndingSoonDays",
+			sub:  Subscription{StartDate: day("2026-01-01"), EndDate: dayPtr("2026-03-31")},
+			now:  "2026-02-28",
+			want: StatusActive,
+		},
+		{
+			name: "пробный период до конца месяца",
+			sub:  Subscription{StartDate: day("2026-01-15"), TrialEndDate: dayPtr("2026-02-01")},
+			now:  "2026-02-28",
+			want: StatusTrialing,
+		},
+		{
+			name: "после пробного периода",
+			sub:  Subscription{StartDate: day("2026-01-15"), TrialEndDate: dayPtr("2026-02-01")},
+			now:  "2026-03-01",
+			want: StatusActive,
+		},
+		{
+			name: "пробный период важнее близкого окончания",
+			sub:  Subscription{StartDate: day("2026-01-01"), EndDate: dayPtr("2026-02-28"), TrialEndDate: dayPtr("2026-02-01")},
+			now:  "2026-02-10",
+			want: StatusTrialing,
+		},
+		{
+			name: "пауза важнее пробного периода",
+			sub: Subscription{
+				StartDate:    day("2026-01-01"),
+				TrialEndDate: dayPtr("2026-06-01"),
+				Pauses:       []Pause{{StartDate: day("2026-02-01"), EndDate: dayPtr("2026-02-15")}},
+			},
+			now:  "2026-02-15",
+			want: StatusPaused,
+		},
+		{
+			name: "пауза важнее близкого окончания",
+			sub: Subscription{
+				StartDate: day("2026-01-01"),
+				EndDate:   dayPtr("2026-03-31"),
+				Pauses:    []Pause{{StartDate: day("2026-03-20")}},
+			},
+			now:  "2026-03-25",
+			want: StatusPaused,
+		},
+		{
+			name: "после закрытой паузы",
+			sub: Subscription{
+				StartDate: day("2026-01-01"),
+				Pauses:    []Pause{{StartDate: day("2026-02-01"), EndDate: dayPtr("2026-02-15")}},
+			},
+			now:  "2026-02-16",
+			want: StatusActive,
+		},
+		{
+			name: "окончание важнее паузы",
+			sub: Subscription{
+				StartDate: day("2026-01-01"),
+				EndDate:   dayPtr("2026-03-31"),
+				Pauses:    []Pause{{StartDate: day("2026-03-20")}},
+			},
+			now:  "2026-04-01",
+			want: StatusExpired,
+		},
+		{
+			name: "время дня не влияет",
+			sub:  Subscription{StartDate: day("2026-03-10")},
+			now:  "2026-03-09T23:59:59Z",
+			want: StatusScheduled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				now = day(tt.now)
+			}
+
+			if got := tt.sub.StatusAt(now); got != tt.want {
+				t.Errorf("StatusAt(%s) = %q, want %q", tt.now, got, tt.want)
+			}
+			// Хранилище отбирает подписки по MayHaveStatus, поэтому оно не должно отсеять точный статус
+			if !tt.sub.MayHaveStatus(tt.want, now) {
+				t.Errorf("MayHaveStatus(%q, %s) = false", tt.want, tt.now)
+			}
+		})
+	}
+}
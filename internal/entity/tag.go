@@ -0,0 +1,29 @@
+package entity
+
+import "strings"
+
+// MaxTagLength — ограничение длины тега, совпадает с размером столбца subscription_tags.tag
+const MaxTagLength = 50
+
+type TagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+// TagUsage — тег и число подписок с ним
+type TagUsage struct {
+	Tag   string `json:"tag" db:"tag"`
+	Count int    `json:"count" db:"count"`
+}
+
+// TagSpend — стоимость подписок с тегом; подписки без тегов собираются под пустым тегом
+type TagSpend struct {
+	Tag       string `json:"tag" db:"tag"`
+	TotalCost int    `json:"total_cost" db:"total_cost"`
+	Count     int    `json:"count" db:"count"`
+}
+
+// NormalizeTag приводит тег к нижнему регистру и убирает лишние пробелы:
+// "Work" и " work " — один тег
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+)
+
+// TrialEnd возвращает последний бесплатный месяц пробного периода из months месяцев с начала start
+func TrialEnd(start time.Time, months int) time.Time {
+	return MonthStart(start).AddDate(0, months-1, 0)
+}
+
+// InTrial сообщает, что месяц month приходится на пробный период и не оплачивается
+func (s *Subscription) InTrial(month time.Time) bool {
+	return s.TrialEndDate != nil && !month.After(*s.TrialEndDate)
+}
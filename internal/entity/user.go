@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCurrency — валюта по умолчанию; ее же получают пользователи, созданные миграцией из существующих подписок
+const DefaultCurrency = "RUB"
+
+type User struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	DisplayName string    `json:"display_name" db:"display_name"`
+	Email       *string   `json:"email,omitempty" db:"email"`
+	// Currency — код ISO 4217, в котором пользователь ведет подписки
+	Currency  string    `json:"currency" db:"currency"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateUserRequest позволяет передать id, если пользователи заводятся во внешней системе
+type CreateUserRequest struct {
+	ID          *uuid.UUID `json:"id,omitempty"`
+	DisplayName string     `json:"display_name" binding:"required,max=255"`
+	Email       *string    `json:"email,omitempty" binding:"omitempty,email,max=255"`
+	Currency    string     `json:"currency,omitempty" binding:"omitempty,iso4217"`
+}
+
+// UpdateUserRequest обновляет переданные поля; пустой email удаляет адрес
+type UpdateUserRequest struct {
+	DisplayName *string `json:"display_name,omitempty" binding:"omitempty,min=1,max=255"`
+	Email       *string `json:"email,omitempty" binding:"omitempty,max=255"`
+	Currency    *string `json:"currency,omitempty" binding:"omitempty,iso4217"`
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BudgetHandler struct {
+	service service.BudgetService
+}
+
+func NewBudgetHandler(service service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{service: service}
+}
+
+// CreateBudget создает бюджет пользователя
+// @Summary Создать бюджет
+// @Description Создает месячный или годовой бюджет на все подписки пользователя, на сервис (service_id или название/алиас из каталога) или на тег. Когда прогноз расходов превышает бюджет, пользователь получает событие budget_exceeded
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body entity.CreateBudgetRequest true "Бюджет"
+// @Success 201 {object} entity.Budget
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/budgets [post]
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req entity.CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budget, err := h.service.CreateBudget(c.Request.Context(), userID, &req)
+	if err != nil {
+		budgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, budget)
+}
+
+// ListBudgets возвращает бюджеты пользователя
+// @Summary Бюджеты пользователя
+// @Description Возвращает бюджеты пользователя в порядке создания
+// @Tags budgets
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {array} entity.Budget
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/budgets [get]
+func (h *BudgetHandler) ListBudgets(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	budgets, err := h.service.ListBudgets(c.Request.Context(), userID)
+	if err != nil {
+		budgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, budgets)
+}
+
+// GetBudgetStatus сравнивает бюджеты с расходами
+// @Summary Исполнение бюджетов
+// @Description Для каждого бюджета возвращает фактические расходы с начала текущего месяца или года по текущий месяц и прогноз на весь период. Расходы считаются как summary с user_id: по цене после скидок, без пробных месяцев, по доле пользователя в совместных подписках
+// @Tags budgets
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {array} entity.BudgetStatus
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/budgets/status [get]
+func (h *BudgetHandler) GetBudgetStatus(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	statuses, err := h.service.GetBudgetStatus(c.Request.Context(), userID)
+	if err != nil {
+		budgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+// UpdateBudget обновляет бюджет
+// @Summary Обновить бюджет
+// @Description Меняет лимит или период бюджета; сервис и тег не меняются
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param budget_id path string true "ID бюджета"
+// @Param request body entity.UpdateBudgetRequest true "Данные для обновления"
+// @Success 200 {object} entity.Budget
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/budgets/{budget_id} [put]
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	userID, id, ok := budgetIDs(c)
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budget, err := h.service.UpdateBudget(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		budgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// DeleteBudget удаляет бюджет
+// @Summary Удалить бюджет
+// @Description Удаляет бюджет пользователя
+// @Tags budgets
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param budget_id path string true "ID бюджета"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/budgets/{budget_id} [delete]
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	userID, id, ok := budgetIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBudget(c.Request.Context(), userID, id); err != nil {
+		budgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "budget deleted successfully"})
+}
+
+// budgetIDs разбирает ID пользователя и бюджета из пути; при ошибке сам отвечает 400
+func budgetIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("budget_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+func budgetError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "user not found", err.Error() == "budget not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "service not found", strings.HasPrefix(err.Error(), "invalid tag"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CatalogHandler struct {
+	service service.CatalogService
+}
+
+func NewCatalogHandler(service service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{service: service}
+}
+
+// CreateService добавляет сервис в каталог
+// @Summary Создать сервис
+// @Description Добавляет сервис в каталог. Название и алиасы используются для сопоставления service_name подписок
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param request body entity.CreateServiceRequest true "Данные сервиса"
+// @Success 201 {object} entity.Service
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services [post]
+func (h *CatalogHandler) CreateService(c *gin.Context) {
+	var req entity.CreateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc, err := h.service.CreateService(c.Request.Context(), &req)
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, svc)
+}
+
+// GetService получает сервис по ID
+// @Summary Получить сервис
+// @Description Возвращает сервис каталога по его ID
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} entity.Service
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetService(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service ID"})
+		return
+	}
+
+	svc, err := h.service.GetService(c.Request.Context(), id)
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, svc)
+}
+
+// UpdateService обновляет сервис
+// @Summary Обновить сервис
+// @Description Обновляет переданные поля сервиса; aliases заменяет список целиком, default_price = 0 убирает цену по умолчанию
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param request body entity.UpdateServiceRequest true "Данные для обновления"
+// @Success 200 {object} entity.Service
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services/{id} [put]
+func (h *CatalogHandler) UpdateService(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service ID"})
+		return
+	}
+
+	var req entity.UpdateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc, err := h.service.UpdateService(c.Request.Context(), id, &req)
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, svc)
+}
+
+// DeleteService удаляет сервис
+// @Summary Удалить сервис
+// @Description Удаляет сервис из каталога, если на него не ссылаются подписки
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) DeleteService(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service ID"})
+		return
+	}
+
+	if err := h.service.DeleteService(c.Request.Context(), id); err != nil {
+		catalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "service deleted successfully"})
+}
+
+// ListServices возвращает каталог сервисов
+// @Summary Список сервисов
+// @Description Возвращает сервисы каталога, отсортированные по названию, с пагинацией
+// @Tags services
+// @Produce json
+// @Param limit query int false "Лимит (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {array} entity.Service
+// @Failure 500 {object} map[string]string
+// @Router /services [get]
+func (h *CatalogHandler) ListServices(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	services, err := h.service.ListServices(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, services)
+}
+
+// catalogError отвечает статусом, соответствующим ошибке каталога
+func catalogError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "service not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "service already exists", "service is in use":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "service name is required":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
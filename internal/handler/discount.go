@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AddDiscount добавляет скидку к подписке
+// @Summary Добавить скидку
+// @Description Добавляет скидку в процентах или фиксированной суммой на months месяцев, до valid_to или бессрочно. Скидка действует не раньше текущего месяца
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body entity.DiscountRequest true "Скидка"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/discounts [post]
+func (h *SubscriptionHandler) AddDiscount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	var req entity.DiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.AddDiscount(c.Request.Context(), id, &req)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// RemoveDiscount удаляет скидку подписки
+// @Summary Удалить скидку
+// @Description Удаляет скидку, которая еще не начала действовать
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param discount_id path string true "ID скидки"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/discounts/{discount_id} [delete]
+func (h *SubscriptionHandler) RemoveDiscount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+	discountID, err := uuid.Parse(c.Param("discount_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid discount ID"})
+		return
+	}
+
+	if err := h.service.RemoveDiscount(c.Request.Context(), id, discountID); err != nil {
+		if err.Error() == "subscription not found" || err.Error() == "discount not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "discount removed successfully"})
+}
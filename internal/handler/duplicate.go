@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListDuplicates возвращает предполагаемые дубликаты подписок
+// @Summary Дубликаты подписок
+// @Description Группирует подписки одного плательщика на один сервис с пересекающимися периодами. Такие подписки учитываются в summary дважды
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "ID плательщика"
+// @Success 200 {array} entity.DuplicateGroup
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/duplicates [get]
+func (h *SubscriptionHandler) ListDuplicates(c *gin.Context) {
+	var userID *uuid.UUID
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		userID = &id
+	}
+
+	groups, err := h.service.ListDuplicates(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// MergeSubscriptions сливает дубликаты в одну подписку
+// @Summary Слить дубликаты
+// @Description Вливает подписки duplicate_ids того же плательщика и сервиса в подписку из пути: ее период расширяется до объединения периодов, теги объединяются, цены, скидки и участники остаются ее собственными. Дубликаты удаляются. Если расширенный период пересекается с другой подпиской, сохраненной при subscriptions.overlap_policy = reject, слияние отклоняется с 409
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки, которая остается"
+// @Param request body entity.MergeRequest true "Дубликаты"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/merge [post]
+func (h *SubscriptionHandler) MergeSubscriptions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	var req entity.MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.MergeSubscriptions(c.Request.Context(), id, &req)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		if isOverlapError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
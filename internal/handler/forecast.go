@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetForecast возвращает прогноз расходов
+// @Summary Прогноз расходов
+// @Description Прогнозирует расходы на months месяцев вперед начиная со следующего месяца, по каждому месяцу в разрезе пользователей и сервисов. Учитывает окончание подписок, запланированные цены, пробные периоды и скидки. Подписки из cancel считаются отмененными, их стоимость показывается как экономия (savings). С user_id учитывается только доля пользователя
+// @Tags subscriptions
+// @Produce json
+// @Param months query int false "Горизонт в месяцах (по умолчанию 12, максимум 36)"
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param cancel query []string false "ID подписок, которые считаются отмененными" collectionFormat(multi)
+// @Success 200 {object} entity.Forecast
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/forecast [get]
+func (h *SubscriptionHandler) GetForecast(c *gin.Context) {
+	var req entity.ForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		req.UserID = &id
+	}
+	for _, raw := range c.QueryArray("cancel") {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID in cancel"})
+			return
+		}
+		req.Cancel = append(req.Cancel, id)
+	}
+
+	forecast, err := h.service.GetForecast(c.Request.Context(), &req)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
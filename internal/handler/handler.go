@@ -1,28 +1,56 @@
 package handler
 
 import (
+	"github.com/ShekleinAleksey/subscriptions/config"
 	_ "github.com/ShekleinAleksey/subscriptions/docs"
+	"github.com/ShekleinAleksey/subscriptions/internal/metrics"
 	"github.com/ShekleinAleksey/subscriptions/internal/service"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Handler struct {
 	SubscriptionHandler *SubscriptionHandler
+	CatalogHandler      *CatalogHandler
+	UserHandler         *UserHandler
+	BudgetHandler       *BudgetHandler
+	HealthHandler       *HealthHandler
+
+	auth config.Auth
 }
 
-func NewHandler(s *service.Service) *Handler {
+func NewHandler(s *service.Service, auth config.Auth) *Handler {
 	return &Handler{
 		SubscriptionHandler: NewSubscriptionHandler(s.SubscriptionService),
+		CatalogHandler:      NewCatalogHandler(s.CatalogService),
+		UserHandler:         NewUserHandler(s.UserService, s.SubscriptionService, s.EventService),
+		BudgetHandler:       NewBudgetHandler(s.BudgetService),
+		HealthHandler:       NewHealthHandler(s.HealthService),
+		auth:                auth,
 	}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	router.Use(
+		otelgin.Middleware("subscriptions"),
+		requestIDMiddleware(),
+		accessLogMiddleware(),
+		metricsMiddleware(),
+		recoveryMiddleware(),
+	)
 
+	router.GET("/healthz", h.HealthHandler.Liveness)
+	router.GET("/readyz", h.HealthHandler.Readiness)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	api := router.Group("/api/v1")
+	api.Use(readPrimaryMiddleware())
+	if h.auth.Enabled {
+		api.Use(authMiddleware(h.auth.APIKeys))
+	}
 	{
 		subscriptions := api.Group("/subscriptions")
 		{
@@ -32,6 +60,50 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			subscriptions.PUT("/:id", h.SubscriptionHandler.UpdateSubscription)
 			subscriptions.DELETE("/:id", h.SubscriptionHandler.DeleteSubscription)
 			subscriptions.GET("/summary", h.SubscriptionHandler.GetSubscriptionSummary)
+			subscriptions.GET("/summary/tags", h.SubscriptionHandler.GetSpendByTag)
+			subscriptions.GET("/settlement", h.SubscriptionHandler.GetSettlement)
+			subscriptions.GET("/forecast", h.SubscriptionHandler.GetForecast)
+			subscriptions.GET("/search", h.SubscriptionHandler.SearchSubscriptions)
+			subscriptions.GET("/duplicates", h.SubscriptionHandler.ListDuplicates)
+			subscriptions.POST("/:id/merge", h.SubscriptionHandler.MergeSubscriptions)
+			subscriptions.GET("/cancellations", h.SubscriptionHandler.GetCancellationReport)
+			subscriptions.POST("/:id/cancel", h.SubscriptionHandler.CancelSubscription)
+			subscriptions.POST("/:id/pause", h.SubscriptionHandler.PauseSubscription)
+			subscriptions.POST("/:id/resume", h.SubscriptionHandler.ResumeSubscription)
+			subscriptions.POST("/:id/tags", h.SubscriptionHandler.AddTags)
+			subscriptions.DELETE("/:id/tags/:tag", h.SubscriptionHandler.RemoveTag)
+			subscriptions.POST("/:id/prices", h.SubscriptionHandler.SchedulePrice)
+			subscriptions.DELETE("/:id/prices/:effective_from", h.SubscriptionHandler.CancelPriceChange)
+			subscriptions.POST("/:id/discounts", h.SubscriptionHandler.AddDiscount)
+			subscriptions.DELETE("/:id/discounts/:discount_id", h.SubscriptionHandler.RemoveDiscount)
+		}
+
+		api.GET("/tags", h.SubscriptionHandler.ListTags)
+
+		services := api.Group("/services")
+		{
+			services.GET("", h.CatalogHandler.ListServices)
+			services.POST("", h.CatalogHandler.CreateService)
+			services.GET("/:id", h.CatalogHandler.GetService)
+			services.PUT("/:id", h.CatalogHandler.UpdateService)
+			services.DELETE("/:id", h.CatalogHandler.DeleteService)
+		}
+
+		users := api.Group("/users")
+		{
+			users.GET("", h.UserHandler.ListUsers)
+			users.POST("", h.UserHandler.CreateUser)
+			users.GET("/:id", h.UserHandler.GetUser)
+			users.PUT("/:id", h.UserHandler.UpdateUser)
+			users.DELETE("/:id", h.UserHandler.DeleteUser)
+			users.GET("/:id/subscriptions", h.UserHandler.ListUserSubscriptions)
+			users.GET("/:id/summary", h.UserHandler.GetUserSummary)
+			users.GET("/:id/events", h.UserHandler.ListUserEvents)
+			users.GET("/:id/budgets", h.BudgetHandler.ListBudgets)
+			users.POST("/:id/budgets", h.BudgetHandler.CreateBudget)
+			users.GET("/:id/budgets/status", h.BudgetHandler.GetBudgetStatus)
+			users.PUT("/:id/budgets/:budget_id", h.BudgetHandler.UpdateBudget)
+			users.DELETE("/:id/budgets/:budget_id", h.BudgetHandler.DeleteBudget)
 		}
 	}
 
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	service service.HealthService
+}
+
+func NewHealthHandler(service service.HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// Liveness сообщает оркестратору, что процесс жив.
+// Эндпоинты проб живут вне /api/v1 и не попадают в Swagger.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Liveness(c.Request.Context()))
+}
+
+// Readiness проверяет готовность принимать трафик
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.service.Readiness(c.Request.Context())
+	if report.Status != entity.HealthStatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
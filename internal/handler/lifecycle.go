@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CancelSubscription отменяет подписку с указанием причины
+// @Summary Отменить подписку
+// @Description Завершает подписку днем effective_date (YYYY-MM-DD или MM-YYYY, по умолчанию сегодня) и сохраняет причину отмены. Дата не может быть в прошлом и позже уже заданной end_date
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body entity.CancelRequest true "Дата и причина отмены"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	var req entity.CancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.CancelSubscription(c.Request.Context(), id, &req)
+	respondLifecycle(c, subscription, err)
+}
+
+// PauseSubscription ставит подписку на паузу
+// @Summary Приостановить подписку
+// @Description Ставит подписку на паузу с effective_date (по умолчанию сегодня) до возобновления. Дни на паузе не входят в summary и прогноз
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body entity.LifecycleRequest false "Дата начала паузы"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/pause [post]
+func (h *SubscriptionHandler) PauseSubscription(c *gin.Context) {
+	h.changeLifecycle(c, h.service.PauseSubscription)
+}
+
+// ResumeSubscription возобновляет подписку после паузы
+// @Summary Возобновить подписку
+// @Description Возобновляет подписку с effective_date (по умолчанию сегодня): пауза заканчивается накануне. Пауза, которая еще не началась, отменяется
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body entity.LifecycleRequest false "Дата возобновления"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/resume [post]
+func (h *SubscriptionHandler) ResumeSubscription(c *gin.Context) {
+	h.changeLifecycle(c, h.service.ResumeSubscription)
+}
+
+// GetCancellationReport возвращает отмены по причинам
+// @Summary Причины отмен
+// @Description Группирует отмененные подписки, закончившиеся в периоде, по причине отмены. monthly_savings — сколько они стоили бы в месяц окончания
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param start_period query string false "Начало периода (MM-YYYY)"
+// @Param end_period query string false "Конец периода (MM-YYYY)"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param status query []string false "Только подписки с указанными статусами на день as_of" collectionFormat(multi)
+// @Param as_of query string false "День, на который вычисляются статусы (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
+// @Success 200 {array} entity.CancellationStats
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/cancellations [get]
+func (h *SubscriptionHandler) GetCancellationReport(c *gin.Context) {
+	req, ok := bindSummaryRequest(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetCancellationReport(c.Request.Context(), req)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// changeLifecycle разбирает запрос паузы или возобновления; тело необязательно
+func (h *SubscriptionHandler) changeLifecycle(c *gin.Context, change func(ctx context.Context, id uuid.UUID, req *entity.LifecycleRequest) (*entity.Subscription, error)) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	var req entity.LifecycleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	subscription, err := change(c.Request.Context(), id, &req)
+	respondLifecycle(c, subscription, err)
+}
+
+// respondLifecycle отвечает подпиской после отмены, паузы или возобновления либо кодом ошибки
+func respondLifecycle(c *gin.Context, subscription *entity.Subscription, err error) {
+	if err != nil {
+		if err.Error() == "subscription not found" || err.Error() == "pause not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
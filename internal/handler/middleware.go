@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/metrics"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength защищает логи от произвольно длинных заголовков клиента
+	maxRequestIDLength = 128
+
+	// readPrimaryHeader со значением true направляет чтения запроса в основную базу вместо реплики
+	readPrimaryHeader = "X-Read-Primary"
+
+	// userContextKey — ключ gin.Context, под которым хранится идентификатор пользователя запроса
+	userContextKey = "user"
+)
+
+// requestIDMiddleware принимает X-Request-ID клиента или генерирует новый,
+// возвращает его в ответе и кладет в контекст запись логгера с request_id
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		entry := logrus.WithField("request_id", requestID)
+		c.Request = c.Request.WithContext(logger.WithEntry(c.Request.Context(), entry))
+
+		c.Next()
+	}
+}
+
+// readPrimaryMiddleware позволяет клиенту прочитать собственные записи сразу после изменения,
+// не дожидаясь репликации
+func readPrimaryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if primary, _ := strconv.ParseBool(c.GetHeader(readPrimaryHeader)); primary {
+			c.Request = c.Request.WithContext(repository.WithPrimary(c.Request.Context()))
+		}
+
+		c.Next()
+	}
+}
+
+// accessLogMiddleware пишет одну структурированную строку на каждый запрос
+func accessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		user := c.GetString(userContextKey)
+		if user == "" {
+			user = c.Query("user_id")
+		}
+
+		entry := logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"route":      c.FullPath(),
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"user":       user,
+			"client_ip":  c.ClientIP(),
+			"bytes":      c.Writer.Size(),
+		})
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("errors", c.Errors.String())
+		}
+
+		switch {
+		case c.Writer.Status() >= http.StatusInternalServerError:
+			entry.Error("Request completed")
+		case c.Writer.Status() >= http.StatusBadRequest:
+			entry.Warn("Request completed")
+		default:
+			entry.Info("Request completed")
+		}
+	}
+}
+
+// recoveryMiddleware превращает панику обработчика в ответ 500 вместо обрыва соединения
+func recoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+					"panic": r,
+					"stack": string(debug.Stack()),
+				}).Error("Recovered from panic")
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// authMiddleware пропускает запросы с известным API-ключом в заголовке
+// Authorization: Bearer <key> или X-API-Key и запоминает имя ключа как пользователя запроса.
+// Ключи задаются в конфиге в формате name:key.
+func authMiddleware(apiKeys []string) gin.HandlerFunc {
+	type apiKey struct{ name, key string }
+	keys := make([]apiKey, 0, len(apiKeys))
+	for _, entry := range apiKeys {
+		name, key, _ := strings.Cut(entry, ":")
+		keys = append(keys, apiKey{name: name, key: key})
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			key = bearer
+		}
+
+		// Сравнение за постоянное время, чтобы ключ нельзя было подобрать по задержке ответа
+		var name string
+		for _, k := range keys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(k.key)) == 1 {
+				name = k.name
+			}
+		}
+		if key == "" || name == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing API key"})
+			return
+		}
+
+		c.Set(userContextKey, name)
+		c.Next()
+	}
+}
+
+// metricsMiddleware считает запросы и их длительность по шаблону маршрута,
+// а не по фактическому пути, чтобы ID в URL не раздували число серий
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), time.Since(start))
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSettlement возвращает взаиморасчеты по совместным подпискам
+// @Summary Взаиморасчеты
+// @Description Считает, сколько участники совместных подписок должны плательщикам за период, со взаимозачетом встречных долгов. С user_id — только долги пользователя и долги ему
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param start_period query string false "Начало периода (MM-YYYY)"
+// @Param end_period query string false "Конец периода (MM-YYYY)"
+// @Param prorate query bool false "Считать неполные месяцы пропорционально дням"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param status query []string false "Только подписки с указанными статусами на день as_of" collectionFormat(multi)
+// @Param as_of query string false "День, на который вычисляются статусы (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
+// @Success 200 {array} entity.Debt
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/settlement [get]
+func (h *SubscriptionHandler) GetSettlement(c *gin.Context) {
+	req, ok := bindSummaryRequest(c)
+	if !ok {
+		return
+	}
+
+	debts, err := h.service.GetSettlement(c.Request.Context(), req)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, debts)
+}
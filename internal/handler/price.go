@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SchedulePrice планирует изменение цены подписки
+// @Summary Запланировать изменение цены
+// @Description Назначает цену с месяца effective_from (не раньше текущего); прошлые месяцы сохраняют прежнюю цену. Цена, уже назначенная на этот месяц, заменяется
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body entity.SchedulePriceRequest true "Новая цена"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/prices [post]
+func (h *SubscriptionHandler) SchedulePrice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	var req entity.SchedulePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.SchedulePrice(c.Request.Context(), id, &req)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// CancelPriceChange отменяет запланированное изменение цены
+// @Summary Отменить изменение цены
+// @Description Удаляет изменение цены с месяца effective_from; начальную и прошлые цены удалить нельзя
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param effective_from path string true "Месяц изменения (MM-YYYY)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/prices/{effective_from} [delete]
+func (h *SubscriptionHandler) CancelPriceChange(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	if err := h.service.CancelPriceChange(c.Request.Context(), id, c.Param("effective_from")); err != nil {
+		if err.Error() == "subscription not found" || err.Error() == "price change not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "price change cancelled successfully"})
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SearchSubscriptions ищет подписки по названию сервиса
+// @Summary Поиск подписок
+// @Description Ищет подписки по названию сервиса без учета регистра и с опечатками: по похожести триграмм (pg_trgm) или вхождению запроса. Результаты упорядочены по убыванию score, в highlight совпавшие фрагменты названия обернуты в <mark>
+// @Tags subscriptions
+// @Produce json
+// @Param q query string true "Запрос"
+// @Param user_id query string false "ID пользователя: подписки, которые он оплачивает или в которых участвует"
+// @Param limit query int false "Лимит (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {array} entity.SearchResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/search [get]
+func (h *SubscriptionHandler) SearchSubscriptions(c *gin.Context) {
+	var req entity.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		req.UserID = &id
+	}
+
+	results, err := h.service.SearchSubscriptions(c.Request.Context(), &req)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -3,9 +3,11 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ShekleinAleksey/subscriptions/internal/entity"
 	"github.com/ShekleinAleksey/subscriptions/internal/service"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -21,13 +23,14 @@ func NewSubscriptionHandler(service service.SubscriptionService) *SubscriptionHa
 
 // CreateSubscription создает новую подписку
 // @Summary Создать подписку
-// @Description Создает новую запись о подписке
+// @Description Создает новую запись о подписке. Сервис задается service_id или названием/алиасом из каталога; неизвестное название добавляется в каталог. Пользователь должен существовать. Пробный период задается trial_end_date или trial_months, скидки — списком discounts. Даты start_date и end_date принимаются в формате YYYY-MM-DD или MM-YYYY (с первого или по последний день месяца), billing_day задает день списания. Пересечение с подпиской того же пользователя на тот же сервис дает предупреждение в warnings или 409, в зависимости от subscriptions.overlap_policy
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param request body entity.CreateSubscriptionRequest true "Данные подписки"
 // @Success 201 {object} entity.Subscription
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
@@ -39,6 +42,14 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 
 	subscription, err := h.service.CreateSubscription(c.Request.Context(), &req)
 	if err != nil {
+		if isOverlapError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -48,10 +59,11 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 
 // GetSubscription получает подписку по ID
 // @Summary Получить подписку
-// @Description Возвращает подписку по её ID
+// @Description Возвращает подписку по её ID. Цена, статус и следующее списание вычисляются на сегодня или на день as_of
 // @Tags subscriptions
 // @Produce json
 // @Param id path string true "ID подписки"
+// @Param as_of query string false "День (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
 // @Success 200 {object} entity.Subscription
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -64,12 +76,16 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 		return
 	}
 
-	subscription, err := h.service.GetSubscription(c.Request.Context(), id)
+	subscription, err := h.service.GetSubscription(c.Request.Context(), id, optionalQuery(c, "as_of"))
 	if err != nil {
 		if err.Error() == "subscription not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
 			return
 		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -79,7 +95,7 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 
 // UpdateSubscription обновляет подписку
 // @Summary Обновить подписку
-// @Description Обновляет данные подписки по ID
+// @Description Обновляет данные подписки по ID. Новая цена действует с текущего месяца, прошлые месяцы сохраняют прежнюю. Пустой trial_end_date убирает пробный период. При subscriptions.overlap_policy = reject изменение сервиса или дат, после которого подписка пересекается с другой подпиской пользователя на тот же сервис, отклоняется с 409
 // @Tags subscriptions
 // @Accept json
 // @Produce json
@@ -88,6 +104,7 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
@@ -108,6 +125,14 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
 			return
 		}
+		if isOverlapError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -147,20 +172,45 @@ func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 
 // ListSubscriptions возвращает список подписок
 // @Summary Список подписок
-// @Description Возвращает список всех подписок с пагинацией
+// @Description Возвращает список всех подписок с пагинацией, упорядоченный по дате начала
 // @Tags subscriptions
 // @Produce json
 // @Param limit query int false "Лимит (по умолчанию 50, максимум 100)"
 // @Param offset query int false "Смещение (по умолчанию 0)"
+// @Param user_id query string false "ID пользователя: подписки, которые он оплачивает или в которых участвует"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param status query []string false "Только подписки с указанными статусами на день as_of" collectionFormat(multi)
+// @Param as_of query string false "День, на который вычисляются статусы (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
 // @Success 200 {array} entity.Subscription
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions [get]
 func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	subscriptions, err := h.service.ListSubscriptions(c.Request.Context(), limit, offset)
+	req := &entity.ListSubscriptionsRequest{
+		Limit:    limit,
+		Offset:   offset,
+		Tags:     c.QueryArray("tag"),
+		Statuses: c.QueryArray("status"),
+		AsOf:     optionalQuery(c, "as_of"),
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		req.UserID = &id
+	}
+
+	subscriptions, err := h.service.ListSubscriptions(c.Request.Context(), req)
 	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -170,37 +220,101 @@ func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
 
 // GetSubscriptionSummary возвращает суммарную стоимость подписок
 // @Summary Суммарная стоимость
-// @Description Возвращает суммарную стоимость подписок за период с фильтрацией
+// @Description Возвращает суммарную стоимость подписок за период: каждый месяц периода, в котором подписка активна, по действовавшей в нем цене с учетом скидок; месяцы пробного периода бесплатны. С prorate=true неполные первый и последний месяцы подписки считаются пропорционально числу дней. Без периода — текущая цена каждой подписки. С user_id по совместным подпискам учитывается только доля пользователя
 // @Tags subscriptions
 // @Produce json
 // @Param user_id query string false "ID пользователя"
 // @Param service_name query string false "Название сервиса"
 // @Param start_period query string true "Начало периода (MM-YYYY)"
 // @Param end_period query string true "Конец периода (MM-YYYY)"
+// @Param prorate query bool false "Считать неполные месяцы пропорционально дням"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param status query []string false "Только подписки с указанными статусами на день as_of" collectionFormat(multi)
+// @Param as_of query string false "День, на который вычисляются статусы (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
 // @Success 200 {object} entity.SubscriptionSummary
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions/summary [get]
 func (h *SubscriptionHandler) GetSubscriptionSummary(c *gin.Context) {
-	var req entity.SubscriptionSummaryRequest
-
-	if err := c.ShouldBindQuery(&req); err != nil {
-		logrus.WithError(err).Warn("Failed to bind query parameters")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	req, ok := bindSummaryRequest(c)
+	if !ok {
 		return
 	}
 
-	summary, err := h.service.GetSubscriptionSummary(c.Request.Context(), &req)
+	summary, err := h.service.GetSubscriptionSummary(c.Request.Context(), req)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get subscription summary")
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to get subscription summary")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
 		"total_cost": summary.TotalCost,
 		"count":      summary.Count,
 	}).Info("Subscription summary calculated successfully")
 
 	c.JSON(http.StatusOK, summary)
 }
+
+// isValidationError сообщает об ошибке в данных запроса: подписка ссылается на сервис,
+// которого нет в каталоге, или на несуществующего пользователя, передала дату в неверном формате, не указала цену,
+// которую нельзя взять из каталога, передала неверный тег, участников, месяц изменения цены
+// или подписки для слияния, недопустимую дату отмены, паузы или возобновления, неверный as_of или статус, пустой поисковый запрос
+func isValidationError(err error) bool {
+	return err.Error() == "service not found" ||
+		err.Error() == "user not found" ||
+		err.Error() == "service_name or service_id is required" ||
+		strings.HasPrefix(err.Error(), "invalid start_date") ||
+		strings.HasPrefix(err.Error(), "invalid end_date") ||
+		strings.HasPrefix(err.Error(), "price is required") ||
+		strings.HasPrefix(err.Error(), "invalid tag") ||
+		strings.HasPrefix(err.Error(), "invalid participant") ||
+		strings.HasPrefix(err.Error(), "invalid effective_from") ||
+		strings.HasPrefix(err.Error(), "invalid trial") ||
+		strings.HasPrefix(err.Error(), "invalid discount") ||
+		strings.HasPrefix(err.Error(), "invalid merge") ||
+		strings.HasPrefix(err.Error(), "invalid cancellation") ||
+		strings.HasPrefix(err.Error(), "invalid pause") ||
+		strings.HasPrefix(err.Error(), "invalid resume") ||
+		strings.HasPrefix(err.Error(), "invalid as_of") ||
+		strings.HasPrefix(err.Error(), "invalid status") ||
+		strings.HasPrefix(err.Error(), "invalid search query")
+}
+
+// isOverlapError сообщает, что подписка отклонена политикой reject из-за пересечения с другой
+func isOverlapError(err error) bool {
+	return strings.HasPrefix(err.Error(), "subscription overlaps")
+}
+
+// optionalQuery возвращает параметр query или nil, если он не передан или пуст
+func optionalQuery(c *gin.Context, key string) *string {
+	if value := c.Query(key); value != "" {
+		return &value
+	}
+	return nil
+}
+
+// bindSummaryRequest читает фильтры summary из query; при ошибке сам отвечает 400
+func bindSummaryRequest(c *gin.Context) (*entity.SubscriptionSummaryRequest, bool) {
+	var req entity.SubscriptionSummaryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to bind query parameters")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return nil, false
+		}
+		req.UserID = &id
+	}
+
+	return &req, true
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AddTags добавляет теги к подписке
+// @Summary Добавить теги
+// @Description Добавляет теги к подписке; теги приводятся к нижнему регистру, уже существующие пропускаются
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body entity.TagsRequest true "Теги"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/tags [post]
+func (h *SubscriptionHandler) AddTags(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	var req entity.TagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.AddTags(c.Request.Context(), id, req.Tags)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// RemoveTag снимает тег с подписки
+// @Summary Удалить тег
+// @Description Снимает тег с подписки
+// @Tags tags
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param tag path string true "Тег"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/tags/{tag} [delete]
+func (h *SubscriptionHandler) RemoveTag(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+		return
+	}
+
+	if err := h.service.RemoveTag(c.Request.Context(), id, c.Param("tag")); err != nil {
+		if err.Error() == "subscription not found" || err.Error() == "tag not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag removed successfully"})
+}
+
+// ListTags возвращает все теги
+// @Summary Список тегов
+// @Description Возвращает все используемые теги с числом подписок
+// @Tags tags
+// @Produce json
+// @Success 200 {array} entity.TagUsage
+// @Failure 500 {object} map[string]string
+// @Router /tags [get]
+func (h *SubscriptionHandler) ListTags(c *gin.Context) {
+	tags, err := h.service.ListTags(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetSpendByTag возвращает стоимость подписок в разрезе тегов
+// @Summary Стоимость по тегам
+// @Description Считает стоимость, как summary, отдельно по каждому тегу. Подписка с несколькими тегами входит в каждый, подписки без тегов — под пустым тегом
+// @Tags tags
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param start_period query string false "Начало периода (MM-YYYY)"
+// @Param end_period query string false "Конец периода (MM-YYYY)"
+// @Param prorate query bool false "Считать неполные месяцы пропорционально дням"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param status query []string false "Только подписки с указанными статусами на день as_of" collectionFormat(multi)
+// @Param as_of query string false "День, на который вычисляются статусы (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
+// @Success 200 {array} entity.TagSpend
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/summary/tags [get]
+func (h *SubscriptionHandler) GetSpendByTag(c *gin.Context) {
+	req, ok := bindSummaryRequest(c)
+	if !ok {
+		return
+	}
+
+	spend, err := h.service.GetSpendByTag(c.Request.Context(), req)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, spend)
+}
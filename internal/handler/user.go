@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UserHandler struct {
+	service       service.UserService
+	subscriptions service.SubscriptionService
+	events        service.EventService
+}
+
+func NewUserHandler(service service.UserService, subscriptions service.SubscriptionService, events service.EventService) *UserHandler {
+	return &UserHandler{service: service, subscriptions: subscriptions, events: events}
+}
+
+// CreateUser создает пользователя
+// @Summary Создать пользователя
+// @Description Создает пользователя; currency по умолчанию RUB
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body entity.CreateUserRequest true "Данные пользователя"
+// @Success 201 {object} entity.User
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req entity.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.CreateUser(c.Request.Context(), &req)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// GetUser получает пользователя по ID
+// @Summary Получить пользователя
+// @Description Возвращает профиль пользователя по его ID
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} entity.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, ok := userID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.GetUser(c.Request.Context(), id)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateUser обновляет пользователя
+// @Summary Обновить пользователя
+// @Description Обновляет переданные поля профиля; пустой email удаляет адрес
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body entity.UpdateUserRequest true "Данные для обновления"
+// @Success 200 {object} entity.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, ok := userID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), id, &req)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser удаляет пользователя
+// @Summary Удалить пользователя
+// @Description Удаляет пользователя, у которого нет подписок
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, ok := userID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteUser(c.Request.Context(), id); err != nil {
+		userError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+}
+
+// ListUsers возвращает список пользователей
+// @Summary Список пользователей
+// @Description Возвращает пользователей в порядке создания с пагинацией
+// @Tags users
+// @Produce json
+// @Param limit query int false "Лимит (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {array} entity.User
+// @Failure 500 {object} map[string]string
+// @Router /users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	users, err := h.service.ListUsers(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// ListUserSubscriptions возвращает подписки пользователя
+// @Summary Подписки пользователя
+// @Description Возвращает подписки пользователя с пагинацией, как GET /subscriptions?user_id=
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param limit query int false "Лимит (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param status query []string false "Только подписки с указанными статусами на день as_of" collectionFormat(multi)
+// @Param as_of query string false "День, на который вычисляются статусы (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
+// @Success 200 {array} entity.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/subscriptions [get]
+func (h *UserHandler) ListUserSubscriptions(c *gin.Context) {
+	id, ok := h.existingUser(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	req := &entity.ListSubscriptionsRequest{
+		Limit:    limit,
+		Offset:   offset,
+		UserID:   &id,
+		Tags:     c.QueryArray("tag"),
+		Statuses: c.QueryArray("status"),
+		AsOf:     optionalQuery(c, "as_of"),
+	}
+	subscriptions, err := h.subscriptions.ListSubscriptions(c.Request.Context(), req)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// ListUserEvents возвращает события пользователя
+// @Summary События пользователя
+// @Description Возвращает события пользователя, сначала новые: например, trial_ending — напоминание о конце пробного периода
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param limit query int false "Лимит (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {array} entity.Event
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/events [get]
+func (h *UserHandler) ListUserEvents(c *gin.Context) {
+	id, ok := h.existingUser(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	events, err := h.events.ListEvents(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetUserSummary возвращает суммарную стоимость подписок пользователя
+// @Summary Суммарная стоимость для пользователя
+// @Description Возвращает суммарную стоимость подписок пользователя, как GET /subscriptions/summary?user_id=
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param start_period query string true "Начало периода (MM-YYYY)"
+// @Param end_period query string true "Конец периода (MM-YYYY)"
+// @Param prorate query bool false "Считать неполные месяцы пропорционально дням"
+// @Param tag query []string false "Только подписки со всеми указанными тегами" collectionFormat(multi)
+// @Param status query []string false "Только подписки с указанными статусами на день as_of" collectionFormat(multi)
+// @Param as_of query string false "День, на который вычисляются статусы (YYYY-MM-DD или MM-YYYY), по умолчанию сегодня"
+// @Success 200 {object} entity.SubscriptionSummary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/summary [get]
+func (h *UserHandler) GetUserSummary(c *gin.Context) {
+	id, ok := h.existingUser(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindSummaryRequest(c)
+	if !ok {
+		return
+	}
+	req.UserID = &id
+
+	summary, err := h.subscriptions.GetSubscriptionSummary(c.Request.Context(), req)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// existingUser читает ID из пути и проверяет, что пользователь существует,
+// чтобы вложенные маршруты отвечали 404, а не пустым списком
+func (h *UserHandler) existingUser(c *gin.Context) (uuid.UUID, bool) {
+	id, ok := userID(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	if _, err := h.service.GetUser(c.Request.Context(), id); err != nil {
+		userError(c, err)
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func userID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// userError отвечает статусом, соответствующим ошибке пользователей
+func userError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "user already exists", err.Error() == "user has subscriptions":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// collectTimeout ограничивает время запроса бизнес-показателей при каждом scrape
+const collectTimeout = 5 * time.Second
+
+type StatsProvider interface {
+	GetActiveStats(ctx context.Context) ([]*entity.ServiceStats, error)
+}
+
+// BusinessCollector считает бизнес-показатели на момент scrape,
+// поэтому они всегда совпадают с данными в базе
+type BusinessCollector struct {
+	provider StatsProvider
+
+	activeSubscriptions *prometheus.Desc
+	monthlySpend        *prometheus.Desc
+	scrapeErrors        prometheus.Counter
+}
+
+func NewBusinessCollector(provider StatsProvider) *BusinessCollector {
+	return &BusinessCollector{
+		provider: provider,
+		activeSubscriptions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "active_subscriptions"),
+			"Количество активных подписок в текущем месяце",
+			[]string{"service_name"}, nil,
+		),
+		monthlySpend: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "monthly_recurring_spend"),
+			"Ежемесячные расходы по активным подпискам",
+			[]string{"service_name"}, nil,
+		),
+		scrapeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "business_scrape_errors_total",
+			Help:      "Количество ошибок при расчете бизнес-показателей",
+		}),
+	}
+}
+
+func (c *BusinessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeSubscriptions
+	ch <- c.monthlySpend
+	c.scrapeErrors.Describe(ch)
+}
+
+func (c *BusinessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	stats, err := c.provider.GetActiveStats(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to collect business metrics")
+		c.scrapeErrors.Inc()
+	}
+
+	for _, s := range stats {
+		ch <- prometheus.MustNewConstMetric(c.activeSubscriptions, prometheus.GaugeValue, float64(s.ActiveCount), s.ServiceName)
+		ch <- prometheus.MustNewConstMetric(c.monthlySpend, prometheus.GaugeValue, float64(s.MonthlySpend), s.ServiceName)
+	}
+	c.scrapeErrors.Collect(ch)
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type BudgetRepository interface {
+	Create(ctx context.Context, budget *entity.Budget) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Budget, error)
+	Update(ctx context.Context, budget *entity.Budget) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Budget, error)
+}
+
+type budgetRepo struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+// NewBudgetRepository создает репозиторий бюджетов; запросы переносимы между Postgres и SQLite
+func NewBudgetRepository(db, replica *sqlx.DB) BudgetRepository {
+	return &budgetRepo{db: db, replica: replica}
+}
+
+const budgetColumns = `id, user_id, period, amount, service_id, tag, created_at`
+
+func (r *budgetRepo) Create(ctx context.Context, budget *entity.Budget) error {
+	query := `
+        INSERT INTO budgets (id, user_id, period, amount, service_id, tag, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `
+
+	err := observeWrite(ctx, "BudgetRepository.Create", query, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, query,
+			budget.ID,
+			budget.UserID,
+			budget.Period,
+			budget.Amount,
+			budget.ServiceID,
+			budget.Tag,
+			budget.CreatedAt,
+		)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to create budget")
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	logger.FromContext(ctx).Infof("Budget created successfully: %s", budget.ID)
+	return nil
+}
+
+func (r *budgetRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE id = $1`
+
+	var budget entity.Budget
+	err := observe(ctx, "BudgetRepository.GetByID", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).GetContext(ctx, &budget, query, id)
+	})
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("budget not found")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to get budget by ID")
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	return &budget, nil
+}
+
+func (r *budgetRepo) Update(ctx context.Context, budget *entity.Budget) error {
+	query := `UPDATE budgets SET period = $1, amount = $2 WHERE id = $3`
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "BudgetRepository.Update", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, budget.Period, budget.Amount, budget.ID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to update budget")
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("budget not found")
+	}
+
+	return nil
+}
+
+func (r *budgetRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := "DELETE FROM budgets WHERE id = $1"
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "BudgetRepository.Delete", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to delete budget")
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("budget not found")
+	}
+
+	return nil
+}
+
+// ListByUser возвращает бюджеты пользователя в порядке создания
+func (r *budgetRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE user_id = $1 ORDER BY created_at, id`
+
+	budgets := []*entity.Budget{}
+	err := observe(ctx, "BudgetRepository.ListByUser", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).SelectContext(ctx, &budgets, query, userID)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to list budgets")
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+
+	return budgets, nil
+}
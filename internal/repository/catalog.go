@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// CatalogRepository хранит каталог сервисов. Названия и алиасы ищутся
+// по ключу entity.NormalizeServiceName и уникальны в пределах каталога.
+type CatalogRepository interface {
+	Create(ctx context.Context, service *entity.Service) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Service, error)
+	FindByName(ctx context.Context, name string) (*entity.Service, error)
+	Update(ctx context.Context, service *entity.Service) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, limit, offset int) ([]*entity.Service, error)
+}
+
+type catalogRepo struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+// NewCatalogRepository создает репозиторий каталога; запросы переносимы между Postgres и SQLite
+func NewCatalogRepository(db, replica *sqlx.DB) CatalogRepository {
+	return &catalogRepo{db: db, replica: replica}
+}
+
+const serviceColumns = `id, name, category, website, default_price`
+
+func (r *catalogRepo) Create(ctx context.Context, service *entity.Service) error {
+	query := `
+        INSERT INTO services (id, name, name_key, category, website, default_price)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+
+	err := observeWrite(ctx, "CatalogRepository.Create", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		_, err = tx.ExecContext(ctx, query,
+			service.ID,
+			service.Name,
+			entity.NormalizeServiceName(service.Name),
+			service.Category,
+			service.Website,
+			service.DefaultPrice,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := insertAliases(ctx, tx, service); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+
+	if isUniqueViolation(err) {
+		return fmt.Errorf("service already exists")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to create service")
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+
+	logger.FromContext(ctx).Infof("Service created successfully: %s", service.ID)
+	return nil
+}
+
+func (r *catalogRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+	query := `SELECT ` + serviceColumns + ` FROM services WHERE id = $1`
+
+	var service entity.Service
+	err := observe(ctx, "CatalogRepository.GetByID", query, func(ctx context.Context) error {
+		db := reader(ctx, r.db, r.replica)
+		if err := db.GetContext(ctx, &service, query, id); err != nil {
+			return err
+		}
+		return loadAliases(ctx, db, []*entity.Service{&service})
+	})
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("service not found")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to get service by ID")
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	return &service, nil
+}
+
+// FindByName ищет сервис по названию или алиасу без учета регистра и лишних пробелов.
+// Читает всегда из основной базы: результат используется при записи подписок.
+func (r *catalogRepo) FindByName(ctx context.Context, name string) (*entity.Service, error) {
+	query := `
+        SELECT ` + serviceColumns + ` FROM services WHERE name_key = $1
+        UNION
+        SELECT s.id, s.name, s.category, s.website, s.default_price
+        FROM services s JOIN service_aliases a ON a.service_id = s.id
+        WHERE a.alias_key = $1
+    `
+
+	var service entity.Service
+	err := observe(ctx, "CatalogRepository.FindByName", query, func(ctx context.Context) error {
+		if err := r.db.GetContext(ctx, &service, query, entity.NormalizeServiceName(name)); err != nil {
+			return err
+		}
+		return loadAliases(ctx, r.db, []*entity.Service{&service})
+	})
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("service not found")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to find service by name")
+		return nil, fmt.Errorf("failed to find service: %w", err)
+	}
+
+	return &service, nil
+}
+
+// Update сохраняет все поля сервиса и заменяет список алиасов.
+// Новое название переносится в service_name подписок этого сервиса.
+func (r *catalogRepo) Update(ctx context.Context, service *entity.Service) error {
+	query := `
+        UPDATE services
+        SET name = $1, name_key = $2, category = $3, website = $4, default_price = $5
+        WHERE id = $6
+    `
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "CatalogRepository.Update", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		result, err := tx.ExecContext(ctx, query,
+			service.Name,
+			entity.NormalizeServiceName(service.Name),
+			service.Category,
+			service.Website,
+			service.DefaultPrice,
+			service.ID,
+		)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, _ = result.RowsAffected(); rowsAffected == 0 {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM service_aliases WHERE service_id = $1`, service.ID); err != nil {
+			return err
+		}
+		if err := insertAliases(ctx, tx, service); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE subscriptions SET service_name = $1 WHERE service_id = $2`, service.Name, service.ID); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+
+	if isUniqueViolation(err) {
+		return fmt.Errorf("service already exists")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to update service")
+		return fmt.Errorf("failed to update service: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("service not found")
+	}
+
+	logger.FromContext(ctx).Infof("Service updated successfully: %s", service.ID)
+	return nil
+}
+
+// Delete удаляет сервис, если на него не ссылается ни одна подписка
+func (r *catalogRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := "DELETE FROM services WHERE id = $1"
+
+	var inUse bool
+	var rowsAffected int64
+	err := observeWrite(ctx, "CatalogRepository.Delete", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		err = tx.GetContext(ctx, &inUse, `SELECT EXISTS (SELECT 1 FROM subscriptions WHERE service_id = $1)`, id)
+		if err != nil || inUse {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+
+		return tx.Commit()
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to delete service")
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+
+	if inUse {
+		return fmt.Errorf("service is in use")
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("service not found")
+	}
+
+	logger.FromContext(ctx).Infof("Service deleted successfully: %s", id)
+	return nil
+}
+
+func (r *catalogRepo) List(ctx context.Context, limit, offset int) ([]*entity.Service, error) {
+	query := `
+        SELECT ` + serviceColumns + `
+        FROM services
+        ORDER BY name_key
+        LIMIT $1 OFFSET $2
+    `
+
+	var services []*entity.Service
+	err := observe(ctx, "CatalogRepository.List", query, func(ctx context.Context) error {
+		db := reader(ctx, r.db, r.replica)
+		if err := db.SelectContext(ctx, &services, query, limit, offset); err != nil {
+			return err
+		}
+		return loadAliases(ctx, db, services)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to list services")
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	return services, nil
+}
+
+func insertAliases(ctx context.Context, tx *sqlx.Tx, service *entity.Service) error {
+	for _, alias := range service.Aliases {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO service_aliases (alias_key, alias, service_id) VALUES ($1, $2, $3)`,
+			entity.NormalizeServiceName(alias), alias, service.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadAliases заполняет Aliases у services одним запросом
+func loadAliases(ctx context.Context, db sqlx.QueryerContext, services []*entity.Service) error {
+	if len(services) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*entity.Service, len(services))
+	placeholders := make([]string, len(services))
+	params := make([]interface{}, len(services))
+	for i, s := range services {
+		s.Aliases = []string{}
+		byID[s.ID] = s
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		params[i] = s.ID
+	}
+
+	query := fmt.Sprintf(`SELECT service_id, alias FROM service_aliases WHERE service_id IN (%s) ORDER BY alias_key`,
+		strings.Join(placeholders, ", "))
+
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serviceID uuid.UUID
+		var alias string
+		if err := rows.Scan(&serviceID, &alias); err != nil {
+			return fmt.Errorf("failed to scan service alias: %w", err)
+		}
+		if s, ok := byID[serviceID]; ok {
+			s.Aliases = append(s.Aliases, alias)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/migrations"
+	"github.com/ShekleinAleksey/subscriptions/pkg/sqlite"
+	"github.com/google/uuid"
+)
+
+// TestSQLiteServiceNameKeys проверяет, что после миграций ключи каталога SQLite совпадают
+// с entity.NormalizeServiceName: 000002 не приводила кириллицу к нижнему регистру и не схлопывала пробелы
+func TestSQLiteServiceNameKeys(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	migrator, err := sqlite.NewMigrator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer migrator.Close()
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	// Схема до 000014, в которой ключи строила 000002
+	if err := migrator.Down(int(migrations.LatestVersion()) - 13); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sqlite.NewDB(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	userID := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	if err := repo.UserRepository.Create(ctx, &entity.User{ID: userID, DisplayName: "Alice", Currency: "RUB"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ключи в том виде, в каком их оставила 000002; у дублей меньший id, чтобы выбор не решался порядком
+	services := []struct {
+		id, name, key string
+	}{
+		{"00000000-0000-0000-0001-000000000001", "Кинопоиск", "Кинопоиск"},
+		{"00000000-0000-0000-0001-000000000002", "кинопоиск", "кинопоиск"},
+		{"00000000-0000-0000-0001-000000000003", "Apple  TV", "apple  tv"},
+		{"00000000-0000-0000-0001-000000000004", "Apple TV", "apple tv"},
+		{"00000000-0000-0000-0001-000000000005", "Окко", "Окко"},
+	}
+	for i, s := range services {
+		if _, err := db.ExecContext(ctx, `INSERT INTO services (id, name, name_key) VALUES ($1, $2, $3)`, s.id, s.name, s.key); err != nil {
+			t.Fatal(err)
+		}
+		sub := &entity.Subscription{
+			ID:          uuid.New(),
+			ServiceID:   uuid.MustParse(s.id),
+			ServiceName: s.name,
+			UserID:      userID,
+			StartDate:   parseDay(t, "2026-01-01"),
+			Prices:      []entity.PricePeriod{{EffectiveFrom: parseDay(t, "2026-01-01"), Price: 100 * (i + 1)}},
+		}
+		if err := repo.SubscriptionRepository.Create(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO budgets (id, user_id, period, amount, service_id) VALUES ($1, $2, 'monthly', 500, $3)`,
+		uuid.New(), userID, services[0].id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"КИНОПОИСК":    services[1].id,
+		" apple   tv ": services[3].id,
+		"окко":         services[4].id,
+	}
+	for name, id := range want {
+		service, err := repo.CatalogRepository.FindByName(ctx, name)
+		if err != nil {
+			t.Fatalf("FindByName(%q): %v", name, err)
+		}
+		if service.ID.String() != id {
+			t.Errorf("FindByName(%q) = %s, want %s", name, service.ID, id)
+		}
+	}
+
+	all, err := repo.CatalogRepository.List(ctx, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("services after merge = %d, want 3", len(all))
+	}
+
+	var orphans int
+	if err := db.GetContext(ctx, &orphans, `
+        SELECT (SELECT COUNT(*) FROM subscriptions WHERE service_id NOT IN (SELECT id FROM services))
+             + (SELECT COUNT(*) FROM budgets WHERE service_id NOT IN (SELECT id FROM services))`); err != nil {
+		t.Fatal(err)
+	}
+	if orphans != 0 {
+		t.Errorf("%d rows still reference merged services", orphans)
+	}
+
+	var names int
+	if err := db.GetContext(ctx, &names, `SELECT COUNT(DISTINCT service_name) FROM subscriptions`); err != nil {
+		t.Fatal(err)
+	}
+	if names != 3 {
+		t.Errorf("distinct service names = %d, want 3", names)
+	}
+}
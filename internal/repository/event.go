@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type EventRepository interface {
+	// Create сохраняет событие и возвращает false, если событие с тем же DedupKey уже есть
+	Create(ctx context.Context, event *entity.Event) (bool, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Event, error)
+}
+
+type eventRepo struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+// NewEventRepository создает репозиторий событий; запросы переносимы между Postgres и SQLite
+func NewEventRepository(db, replica *sqlx.DB) EventRepository {
+	return &eventRepo{db: db, replica: replica}
+}
+
+func (r *eventRepo) Create(ctx context.Context, event *entity.Event) (bool, error) {
+	query := `
+        INSERT INTO events (id, type, user_id, subscription_id, message, dedup_key, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (dedup_key) DO NOTHING
+    `
+
+	var dedupKey *string
+	if event.DedupKey != "" {
+		dedupKey = &event.DedupKey
+	}
+
+	var created int64
+	err := observeWrite(ctx, "EventRepository.Create", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query,
+			event.ID,
+			event.Type,
+			event.UserID,
+			event.SubscriptionID,
+			event.Message,
+			dedupKey,
+			event.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		created, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to create event")
+		return false, fmt.Errorf("failed to create event: %w", err)
+	}
+
+	return created > 0, nil
+}
+
+// ListByUser возвращает события пользователя, сначала новые
+func (r *eventRepo) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Event, error) {
+	query := `
+        SELECT id, type, user_id, subscription_id, message, created_at
+        FROM events
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id
+        LIMIT $2 OFFSET $3
+    `
+
+	events := []*entity.Event{}
+	err := observe(ctx, "EventRepository.ListByUser", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).SelectContext(ctx, &events, query, userID, limit, offset)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to list events")
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	return events, nil
+}
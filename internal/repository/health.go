@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	HasReplica() bool
+	PingReplica(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+type healthRepo struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+func NewHealthRepository(db, replica *sqlx.DB) HealthRepository {
+	return &healthRepo{db: db, replica: replica}
+}
+
+func (r *healthRepo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *healthRepo) HasReplica() bool {
+	return r.replica != nil
+}
+
+func (r *healthRepo) PingReplica(ctx context.Context) error {
+	if r.replica == nil {
+		return nil
+	}
+	return r.replica.PingContext(ctx)
+}
+
+func (r *healthRepo) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+	var version uint
+	var dirty bool
+	if err := r.db.QueryRowContext(ctx, query).Scan(&version, &dirty); err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, dirty, nil
+}
@@ -0,0 +1,899 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/migrations"
+	"github.com/ShekleinAleksey/subscriptions/pkg/trigram"
+	"github.com/google/uuid"
+)
+
+// NewMemoryRepository создает репозитории, хранящие данные в памяти процесса.
+// Подходит для локальной разработки без Postgres и для тестов; данные теряются при остановке.
+func NewMemoryRepository(seed []*entity.Subscription) (*Repository, error) {
+	subscriptions := newMemorySubscriptionRepo()
+	budgets := newMemoryBudgetRepo()
+	catalog := newMemoryCatalogRepo(subscriptions, budgets)
+	events := newMemoryEventRepo()
+	users := newMemoryUserRepo(subscriptions, events, budgets)
+	for _, s := range seed {
+		if s.ID == uuid.Nil {
+			s.ID = uuid.New()
+		}
+		// Пользователи из начальных данных заводятся с профилем по умолчанию, как в миграции users
+		userIDs := []uuid.UUID{s.UserID}
+		for _, p := range s.Participants {
+			userIDs = append(userIDs, p.UserID)
+		}
+		for _, id := range userIDs {
+			if _, err := users.GetByID(context.Background(), id); err == nil {
+				continue
+			}
+			user := &entity.User{ID: id, Currency: entity.DefaultCurrency, CreatedAt: time.Now().UTC()}
+			if err := users.Create(context.Background(), user); err != nil {
+				return nil, fmt.Errorf("failed to seed user %s: %w", id, err)
+			}
+		}
+		// service_id из выгрузки другой базы не имеет смысла — сервис определяется по названию
+		service, err := catalog.resolve(s.ServiceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed service %q: %w", s.ServiceName, err)
+		}
+		s.ServiceID, s.ServiceName = service.ID, service.Name
+		// В выгрузке до появления истории цен есть только price
+		if len(s.Prices) == 0 {
+			s.Prices = []entity.PricePeriod{{EffectiveFrom: s.StartDate, Price: s.Price}}
+		}
+
+		if err := subscriptions.Create(context.Background(), s); err != nil {
+			return nil, fmt.Errorf("failed to seed subscription %s: %w", s.ID, err)
+		}
+	}
+
+	return &Repository{
+		SubscriptionRepository: subscriptions,
+		CatalogRepository:      catalog,
+		UserRepository:         users,
+		EventRepository:        events,
+		BudgetRepository:       budgets,
+		HealthRepository:       memoryHealthRepo{},
+	}, nil
+}
+
+// LoadSeed читает начальные данные из JSON-массива подписок — формата команды export
+func LoadSeed(path string) ([]*entity.Subscription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	var seed []*entity.Subscription
+	if err := json.Unmarshal(data, &seed); err != nil {
+		return nil, fmt.Errorf("failed to parse seed file %s: %w", path, err)
+	}
+
+	return seed, nil
+}
+
+type memorySubscriptionRepo struct {
+	mu            sync.RWMutex
+	subscriptions map[uuid.UUID]*entity.Subscription
+}
+
+func newMemorySubscriptionRepo() *memorySubscriptionRepo {
+	return &memorySubscriptionRepo{subscriptions: make(map[uuid.UUID]*entity.Subscription)}
+}
+
+func (r *memorySubscriptionRepo) Create(ctx context.Context, subscription *entity.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subscriptions[subscription.ID]; exists {
+		return fmt.Errorf("failed to create subscription: duplicate id %s", subscription.ID)
+	}
+	if len(subscription.Prices) == 0 {
+		return fmt.Errorf("failed to create subscription: price is required")
+	}
+	for _, p := range subscription.Prices {
+		if p.Price <= 0 {
+			return fmt.Errorf("failed to create subscription: price must be positive")
+		}
+	}
+
+	stored := cloneSubscription(subscription)
+	stored.Tags = sortedTags(stored.Tags)
+	stored.Discounts = sortedDiscounts(stored.Discounts)
+	stored.Participants = sortedParticipants(stored.Participants)
+	r.subscriptions[subscription.ID] = stored
+	return nil
+}
+
+func (r *memorySubscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, fmt.Errorf("subscription not found")
+	}
+
+	return cloneSubscription(subscription), nil
+}
+
+func (r *memorySubscriptionRepo) Update(ctx context.Context, id uuid.UUID, req *entity.UpdateSubscriptionRequest) error {
+	if req.ServiceID == nil && req.ServiceName == nil && req.Price == nil && req.StartDate == nil && req.EndDate == nil && req.TrialEndDate == nil && req.BillingDay == nil && req.Tags == nil && req.Participants == nil {
+		return fmt.Errorf("no fields to update")
+	}
+
+	// Разбираем даты до блокировки, как и Postgres-реализация — до запроса
+	var startDate, endDate *time.Time
+	if req.StartDate != nil {
+		parsed, err := entity.ParseDate(*req.StartDate)
+		if err != nil {
+			return fmt.Errorf("invalid start_date format: %w", err)
+		}
+		startDate = &parsed
+	}
+	if req.EndDate != nil && *req.EndDate != "" {
+		parsed, err := entity.ParseEndDate(*req.EndDate)
+		if err != nil {
+			return fmt.Errorf("invalid end_date format: %w", err)
+		}
+		endDate = &parsed
+	}
+	if req.Price != nil && *req.Price <= 0 {
+		return fmt.Errorf("failed to update subscription: price must be positive")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription not found")
+	}
+
+	if req.ServiceID != nil {
+		subscription.ServiceID = *req.ServiceID
+	}
+	if req.ServiceName != nil {
+		subscription.ServiceName = *req.ServiceName
+	}
+	if req.Price != nil {
+		setPrice(subscription, entity.PricePeriod{EffectiveFrom: req.PriceFrom, Price: *req.Price})
+	}
+	if startDate != nil {
+		subscription.StartDate = *startDate
+	}
+	if req.EndDate != nil {
+		subscription.EndDate = endDate
+	}
+	if req.TrialEndDate != nil {
+		subscription.TrialEndDate = cloneTime(req.TrialEnd)
+	}
+	if req.BillingDay != nil {
+		subscription.BillingDay = cloneInt(req.BillingDay)
+	}
+	if req.Exclusive != nil {
+		subscription.Exclusive = *req.Exclusive
+	}
+	if req.Tags != nil {
+		subscription.Tags = sortedTags(*req.Tags)
+	}
+	if req.Participants != nil {
+		subscription.Participants = sortedParticipants(*req.Participants)
+	}
+
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return fmt.Errorf("subscription not found")
+	}
+
+	delete(r.subscriptions, id)
+
+	return nil
+}
+
+// List сортирует подписки по дате начала, как ORDER BY start_date, id в SQL
+func (r *memorySubscriptionRepo) List(ctx context.Context, req *entity.ListSubscriptionsRequest) ([]*entity.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*entity.Subscription
+	for _, s := range r.subscriptions {
+		if req.UserID != nil && !isParticipant(s, *req.UserID) || !hasAllTags(s, req.Tags) || !mayHaveStatus(s, req) {
+			continue
+		}
+		if req.After != nil && !afterCursor(s, req.After) {
+			continue
+		}
+		matched = append(matched, s)
+	}
+	sortByStart(matched)
+
+	var subscriptions []*entity.Subscription
+	for i := req.Offset; i < len(matched) && len(subscriptions) < req.Limit; i++ {
+		subscriptions = append(subscriptions, cloneSubscription(matched[i]))
+	}
+
+	return subscriptions, nil
+}
+
+// afterCursor сообщает, что s идет после позиции cursor в порядке start_date, id
+func afterCursor(s *entity.Subscription, cursor *entity.ListCursor) bool {
+	if !s.StartDate.Equal(cursor.StartDate) {
+		return s.StartDate.After(cursor.StartDate)
+	}
+	return s.ID.String() > cursor.ID.String()
+}
+
+// mayHaveStatus сообщает, что даты s допускают один из статусов req; без статусов подходит любая подписка
+func mayHaveStatus(s *entity.Subscription, req *entity.ListSubscriptionsRequest) bool {
+	if len(req.Statuses) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(req.Statuses, func(status string) bool { return s.MayHaveStatus(status, req.StatusDate) })
+}
+
+// Find сортирует подписки по дате начала, как ORDER BY start_date, id в SQL
+func (r *memorySubscriptionRepo) Find(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.Subscription, error) {
+	match, err := summaryMatcher(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscriptions []*entity.Subscription
+	for _, s := range r.subscriptions {
+		if match(s) {
+			subscriptions = append(subscriptions, cloneSubscription(s))
+		}
+	}
+	sortByStart(subscriptions)
+
+	return subscriptions, nil
+}
+
+func (r *memorySubscriptionRepo) SetPrice(ctx context.Context, id uuid.UUID, period entity.PricePeriod) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription not found")
+	}
+
+	setPrice(subscription, period)
+	return nil
+}
+
+func (r *memorySubscriptionRepo) DeletePrice(ctx context.Context, id uuid.UUID, effectiveFrom time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("price change not found")
+	}
+
+	for i, p := range subscription.Prices {
+		if p.EffectiveFrom.Equal(effectiveFrom) {
+			subscription.Prices = slices.Delete(subscription.Prices, i, i+1)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("price change not found")
+}
+
+func (r *memorySubscriptionRepo) AddDiscount(ctx context.Context, id uuid.UUID, discount entity.Discount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription not found")
+	}
+
+	subscription.Discounts = sortedDiscounts(append(subscription.Discounts, cloneDiscount(discount)))
+	return nil
+}
+
+func (r *memorySubscriptionRepo) DeleteDiscount(ctx context.Context, id, discountID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("discount not found")
+	}
+
+	i := slices.IndexFunc(subscription.Discounts, func(d entity.Discount) bool { return d.ID == discountID })
+	if i < 0 {
+		return fmt.Errorf("discount not found")
+	}
+	subscription.Discounts = slices.Delete(subscription.Discounts, i, i+1)
+
+	return nil
+}
+
+// sortedDiscounts сортирует скидки, как ORDER BY valid_from, id в SQL
+func sortedDiscounts(discounts []entity.Discount) []entity.Discount {
+	sort.Slice(discounts, func(i, j int) bool {
+		if !discounts[i].ValidFrom.Equal(discounts[j].ValidFrom) {
+			return discounts[i].ValidFrom.Before(discounts[j].ValidFrom)
+		}
+		return discounts[i].ID.String() < discounts[j].ID.String()
+	})
+	return discounts
+}
+
+func cloneDiscount(d entity.Discount) entity.Discount {
+	d.Percent, d.Amount = cloneInt(d.Percent), cloneInt(d.Amount)
+	if d.ValidTo != nil {
+		validTo := *d.ValidTo
+		d.ValidTo = &validTo
+	}
+	return d
+}
+
+// setPrice повторяет upsert в subscription_prices, сохраняя порядок по EffectiveFrom
+func setPrice(s *entity.Subscription, period entity.PricePeriod) {
+	for i, p := range s.Prices {
+		if p.EffectiveFrom.Equal(period.EffectiveFrom) {
+			s.Prices[i].Price = period.Price
+			return
+		}
+	}
+	s.Prices = append(s.Prices, period)
+	sort.Slice(s.Prices, func(i, j int) bool { return s.Prices[i].EffectiveFrom.Before(s.Prices[j].EffectiveFrom) })
+}
+
+// summaryMatcher повторяет фильтры SQL-запроса subscriptionRepo.Find:
+// подписка попадает в период, если началась не позже его конца и не закончилась до его начала,
+// а статус на день as_of проверяется через StatusAt
+func summaryMatcher(req *entity.SubscriptionSummaryRequest) (func(s *entity.Subscription) bool, error) {
+	var startPeriod, endPeriod *time.Time
+	if req.StartPeriod != nil && req.EndPeriod != nil {
+		start, err := time.Parse("01-2006", *req.StartPeriod)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_period format: %w", err)
+		}
+		end, err := time.Parse("01-2006", *req.EndPeriod)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_period format: %w", err)
+		}
+		end = entity.MonthEnd(end)
+		startPeriod, endPeriod = &start, &end
+	}
+
+	asOf := req.AsOfDay(time.Now())
+
+	return func(s *entity.Subscription) bool {
+		if startPeriod != nil && (s.StartDate.After(*endPeriod) || (s.EndDate != nil && s.EndDate.Before(*startPeriod))) {
+			return false
+		}
+		if req.UserID != nil && !isParticipant(s, *req.UserID) {
+			return false
+		}
+		if req.ServiceName != nil && s.ServiceName != *req.ServiceName {
+			return false
+		}
+		if req.Shared != nil && *req.Shared != (len(s.Participants) > 0) {
+			return false
+		}
+		if len(req.Statuses) > 0 && !slices.Contains(req.Statuses, s.StatusAt(asOf)) {
+			return false
+		}
+		return hasAllTags(s, req.Tags)
+	}, nil
+}
+
+// Summary считает то же, что SQL-запрос subscriptionRepo.Summary, через entity.SummaryWindow.Charges
+func (r *memorySubscriptionRepo) Summary(ctx context.Context, req *entity.SubscriptionSummaryRequest) (*entity.SubscriptionSummary, error) {
+	window, err := req.Window(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := r.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &entity.SubscriptionSummary{}
+	for _, s := range subscriptions {
+		window.Charges(s, func(_ time.Time, price int) { summary.TotalCost += price })
+		summary.Count++
+	}
+	return summary, nil
+}
+
+func (r *memorySubscriptionRepo) SpendByTag(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.TagSpend, error) {
+	window, err := req.Window(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := r.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	byTag := make(map[string]*entity.TagSpend)
+	for _, s := range subscriptions {
+		cost := 0
+		window.Charges(s, func(_ time.Time, price int) { cost += price })
+		tags := s.Tags
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		for _, tag := range tags {
+			spend, ok := byTag[tag]
+			if !ok {
+				spend = &entity.TagSpend{Tag: tag}
+				byTag[tag] = spend
+			}
+			spend.TotalCost += cost
+			spend.Count++
+		}
+	}
+
+	spend := make([]*entity.TagSpend, 0, len(byTag))
+	for _, s := range byTag {
+		spend = append(spend, s)
+	}
+	sort.Slice(spend, func(i, j int) bool { return spend[i].Tag < spend[j].Tag })
+	return spend, nil
+}
+
+func (r *memorySubscriptionRepo) MonthlySpend(ctx context.Context, req *entity.SubscriptionSummaryRequest, cancel []uuid.UUID) ([]*entity.MonthSpend, error) {
+	window, err := req.Window(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := r.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		month       time.Time
+		serviceName string
+		userID      uuid.UUID
+		cancelled   bool
+	}
+	byKey := make(map[key]*entity.MonthSpend)
+	spend := []*entity.MonthSpend{}
+	for _, s := range subscriptions {
+		cancelled := slices.Contains(cancel, s.ID)
+		window.Charges(s, func(month time.Time, price int) {
+			k := key{month, s.ServiceName, s.UserID, cancelled}
+			row, ok := byKey[k]
+			if !ok {
+				row = &entity.MonthSpend{Month: month, ServiceName: s.ServiceName, UserID: s.UserID, Cancelled: cancelled}
+				byKey[k] = row
+				spend = append(spend, row)
+			}
+			row.Cost += price
+		})
+	}
+	sortMonthSpend(spend)
+	return spend, nil
+}
+
+func (r *memorySubscriptionRepo) ActiveStats(ctx context.Context, month time.Time) ([]*entity.ServiceStats, error) {
+	period := month.Format(entity.MonthLayout)
+	subscriptions, err := r.Find(ctx, &entity.SubscriptionSummaryRequest{StartPeriod: &period, EndPeriod: &period})
+	if err != nil {
+		return nil, err
+	}
+
+	window := entity.SummaryWindow{From: month, To: month}
+	byService := make(map[string]*entity.ServiceStats)
+	stats := []*entity.ServiceStats{}
+	for _, s := range subscriptions {
+		st, ok := byService[s.ServiceName]
+		if !ok {
+			st = &entity.ServiceStats{ServiceName: s.ServiceName}
+			byService[s.ServiceName] = st
+			stats = append(stats, st)
+		}
+		st.ActiveCount++
+		window.Charges(s, func(_ time.Time, price int) { st.MonthlySpend += price })
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ServiceName < stats[j].ServiceName })
+	return stats, nil
+}
+
+// TrialsEnding сортирует по концу пробного периода, как ORDER BY trial_end_date, id в SQL
+func (r *memorySubscriptionRepo) TrialsEnding(ctx context.Context, from, to time.Time) ([]*entity.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscriptions []*entity.Subscription
+	for _, s := range r.subscriptions {
+		if s.TrialEndDate != nil && !s.TrialEndDate.Before(from) && !s.TrialEndDate.After(to) {
+			subscriptions = append(subscriptions, cloneSubscription(s))
+		}
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].TrialEndDate.Equal(*subscriptions[j].TrialEndDate) {
+			return subscriptions[i].TrialEndDate.Before(*subscriptions[j].TrialEndDate)
+		}
+		return subscriptions[i].ID.String() < subscriptions[j].ID.String()
+	})
+
+	return subscriptions, nil
+}
+
+func (r *memorySubscriptionRepo) FindOverlapping(ctx context.Context, userID, serviceID uuid.UUID, start time.Time, end *time.Time) ([]*entity.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscriptions []*entity.Subscription
+	for _, s := range r.subscriptions {
+		if s.UserID == userID && s.ServiceID == serviceID && s.OverlapsPeriod(start, end) {
+			subscriptions = append(subscriptions, cloneSubscription(s))
+		}
+	}
+	sortByStart(subscriptions)
+
+	return subscriptions, nil
+}
+
+// FindDuplicates сортирует, как ORDER BY user_id, service_id, start_date, id в SQL
+func (r *memorySubscriptionRepo) FindDuplicates(ctx context.Context, userID *uuid.UUID) ([]*entity.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscriptions []*entity.Subscription
+	for _, s := range r.subscriptions {
+		if userID != nil && s.UserID != *userID {
+			continue
+		}
+		for _, other := range r.subscriptions {
+			if s.Duplicates(other) {
+				subscriptions = append(subscriptions, cloneSubscription(s))
+				break
+			}
+		}
+	}
+	sortByStart(subscriptions)
+	sort.SliceStable(subscriptions, func(i, j int) bool {
+		if subscriptions[i].UserID != subscriptions[j].UserID {
+			return subscriptions[i].UserID.String() < subscriptions[j].UserID.String()
+		}
+		return subscriptions[i].ServiceID.String() < subscriptions[j].ServiceID.String()
+	})
+
+	return subscriptions, nil
+}
+
+func (r *memorySubscriptionRepo) Merge(ctx context.Context, target *entity.Subscription, duplicateIDs []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[target.ID]
+	if !ok {
+		return fmt.Errorf("subscription not found")
+	}
+	for _, id := range duplicateIDs {
+		if _, ok := r.subscriptions[id]; !ok {
+			return fmt.Errorf("subscription not found")
+		}
+	}
+
+	subscription.StartDate = target.StartDate
+	subscription.EndDate = nil
+	if target.EndDate != nil {
+		endDate := *target.EndDate
+		subscription.EndDate = &endDate
+	}
+	subscription.Tags = sortedTags(append(subscription.Tags, target.Tags...))
+	for _, id := range duplicateIDs {
+		delete(r.subscriptions, id)
+	}
+
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, reason string, comment *string, cancelledAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription not found")
+	}
+
+	subscription.EndDate = &endDate
+	subscription.CancelReason = &reason
+	subscription.CancelComment = cloneString(comment)
+	subscription.CancelledAt = &cancelledAt
+	return nil
+}
+
+func (r *memorySubscriptionRepo) AddPause(ctx context.Context, id uuid.UUID, pause entity.Pause) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("failed to pause subscription: subscription %s does not exist", id)
+	}
+
+	subscription.Pauses = append(subscription.Pauses, clonePause(pause))
+	sort.Slice(subscription.Pauses, func(i, j int) bool {
+		return subscription.Pauses[i].StartDate.Before(subscription.Pauses[j].StartDate)
+	})
+	return nil
+}
+
+func (r *memorySubscriptionRepo) EndPause(ctx context.Context, id, pauseID uuid.UUID, end time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if subscription, ok := r.subscriptions[id]; ok {
+		for i := range subscription.Pauses {
+			if subscription.Pauses[i].ID == pauseID {
+				subscription.Pauses[i].EndDate = &end
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("pause not found")
+}
+
+func (r *memorySubscriptionRepo) DeletePause(ctx context.Context, id, pauseID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if subscription, ok := r.subscriptions[id]; ok {
+		for i, p := range subscription.Pauses {
+			if p.ID == pauseID {
+				subscription.Pauses = append(subscription.Pauses[:i], subscription.Pauses[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("pause not found")
+}
+
+func clonePause(p entity.Pause) entity.Pause {
+	if p.EndDate != nil {
+		endDate := *p.EndDate
+		p.EndDate = &endDate
+	}
+	return p
+}
+
+// sortByStart сортирует подписки по дате начала, как ORDER BY start_date, id в SQL
+func sortByStart(subscriptions []*entity.Subscription) {
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].StartDate.Equal(subscriptions[j].StartDate) {
+			return subscriptions[i].StartDate.Before(subscriptions[j].StartDate)
+		}
+		return subscriptions[i].ID.String() < subscriptions[j].ID.String()
+	})
+}
+
+func (r *memorySubscriptionRepo) Search(ctx context.Context, req *entity.SearchRequest) ([]*entity.SearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscriptions []*entity.Subscription
+	for _, s := range r.subscriptions {
+		if req.UserID == nil || isParticipant(s, *req.UserID) {
+			subscriptions = append(subscriptions, cloneSubscription(s))
+		}
+	}
+
+	return rankSearch(subscriptions, req), nil
+}
+
+// rankSearch повторяет поиск pg_trgm для хранилищ без него: оставляет подписки, название которых
+// похоже на запрос или содержит его, и возвращает страницу по убыванию похожести,
+// как ORDER BY score DESC, service_name, id в SQL
+func rankSearch(subscriptions []*entity.Subscription, req *entity.SearchRequest) []*entity.SearchResult {
+	var results []*entity.SearchResult
+	for _, s := range subscriptions {
+		if trigram.Match(s.ServiceName, req.Query) {
+			results = append(results, &entity.SearchResult{Subscription: s, Score: trigram.Similarity(s.ServiceName, req.Query)})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Subscription.ServiceName != b.Subscription.ServiceName {
+			return a.Subscription.ServiceName < b.Subscription.ServiceName
+		}
+		return a.Subscription.ID.String() < b.Subscription.ID.String()
+	})
+
+	if req.Offset >= len(results) {
+		return nil
+	}
+	return results[req.Offset:min(req.Offset+req.Limit, len(results))]
+}
+
+func (r *memorySubscriptionRepo) AddTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription not found")
+	}
+
+	subscription.Tags = sortedTags(append(subscription.Tags, tags...))
+	return nil
+}
+
+func (r *memorySubscriptionRepo) RemoveTag(ctx context.Context, id uuid.UUID, tag string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription not found")
+	}
+
+	i := slices.Index(subscription.Tags, tag)
+	if i < 0 {
+		return fmt.Errorf("tag not found")
+	}
+	subscription.Tags = slices.Delete(subscription.Tags, i, i+1)
+
+	return nil
+}
+
+func (r *memorySubscriptionRepo) ListTags(ctx context.Context) ([]*entity.TagUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, s := range r.subscriptions {
+		for _, tag := range s.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]*entity.TagUsage, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, &entity.TagUsage{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+
+	return tags, nil
+}
+
+func hasAllTags(s *entity.Subscription, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(s.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedTags убирает повторы и сортирует теги, как их возвращает SQL-реализация
+func sortedTags(tags []string) []string {
+	sorted := slices.Clone(tags)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+func cloneSubscription(s *entity.Subscription) *entity.Subscription {
+	clone := *s
+	clone.Tags = append([]string{}, s.Tags...)
+	clone.Prices = append([]entity.PricePeriod{}, s.Prices...)
+	clone.Discounts = make([]entity.Discount, len(s.Discounts))
+	for i, d := range s.Discounts {
+		clone.Discounts[i] = cloneDiscount(d)
+	}
+	clone.Participants = make([]entity.Participant, len(s.Participants))
+	for i, p := range s.Participants {
+		clone.Participants[i] = entity.Participant{UserID: p.UserID, Weight: cloneInt(p.Weight), Amount: cloneInt(p.Amount)}
+	}
+	if s.EndDate != nil {
+		endDate := *s.EndDate
+		clone.EndDate = &endDate
+	}
+	if s.TrialEndDate != nil {
+		trialEndDate := *s.TrialEndDate
+		clone.TrialEndDate = &trialEndDate
+	}
+	clone.BillingDay = cloneInt(s.BillingDay)
+	clone.Pauses = make([]entity.Pause, len(s.Pauses))
+	for i, p := range s.Pauses {
+		clone.Pauses[i] = clonePause(p)
+	}
+	clone.CancelReason = cloneString(s.CancelReason)
+	clone.CancelComment = cloneString(s.CancelComment)
+	if s.CancelledAt != nil {
+		cancelledAt := *s.CancelledAt
+		clone.CancelledAt = &cancelledAt
+	}
+	clone.Evaluate(time.Now())
+	return &clone
+}
+
+// isParticipant сообщает, оплачивает ли пользователь подписку или участвует в ней
+func isParticipant(s *entity.Subscription, userID uuid.UUID) bool {
+	if s.UserID == userID {
+		return true
+	}
+	for _, p := range s.Participants {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedParticipants сортирует участников по user_id, как их возвращает SQL-реализация
+func sortedParticipants(participants []entity.Participant) []entity.Participant {
+	sorted := slices.Clone(participants)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].UserID.String() < sorted[j].UserID.String() })
+	return sorted
+}
+
+func cloneString(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	clone := *v
+	return &clone
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	clone := *v
+	return &clone
+}
+
+func cloneTime(v *time.Time) *time.Time {
+	if v == nil {
+		return nil
+	}
+	clone := *v
+	return &clone
+}
+
+// memoryHealthRepo всегда здоров: у хранилища в памяти нет внешних зависимостей и схемы
+type memoryHealthRepo struct{}
+
+func (memoryHealthRepo) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (memoryHealthRepo) HasReplica() bool {
+	return false
+}
+
+func (memoryHealthRepo) PingReplica(ctx context.Context) error {
+	return nil
+}
+
+func (memoryHealthRepo) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	return migrations.LatestVersion(), false, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+)
+
+type memoryBudgetRepo struct {
+	mu      sync.RWMutex
+	budgets map[uuid.UUID]*entity.Budget
+}
+
+func newMemoryBudgetRepo() *memoryBudgetRepo {
+	return &memoryBudgetRepo{budgets: make(map[uuid.UUID]*entity.Budget)}
+}
+
+func (r *memoryBudgetRepo) Create(ctx context.Context, budget *entity.Budget) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.budgets[budget.ID]; exists {
+		return fmt.Errorf("failed to create budget: duplicate id %s", budget.ID)
+	}
+
+	r.budgets[budget.ID] = cloneBudget(budget)
+	return nil
+}
+
+func (r *memoryBudgetRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Budget, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	budget, ok := r.budgets[id]
+	if !ok {
+		return nil, fmt.Errorf("budget not found")
+	}
+
+	return cloneBudget(budget), nil
+}
+
+func (r *memoryBudgetRepo) Update(ctx context.Context, budget *entity.Budget) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.budgets[budget.ID]
+	if !ok {
+		return fmt.Errorf("budget not found")
+	}
+
+	stored.Period, stored.Amount = budget.Period, budget.Amount
+	return nil
+}
+
+func (r *memoryBudgetRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.budgets[id]; !ok {
+		return fmt.Errorf("budget not found")
+	}
+
+	delete(r.budgets, id)
+	return nil
+}
+
+// ListByUser сортирует по дате создания, как ORDER BY created_at, id в SQL
+func (r *memoryBudgetRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Budget, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	budgets := []*entity.Budget{}
+	for _, b := range r.budgets {
+		if b.UserID == userID {
+			budgets = append(budgets, cloneBudget(b))
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		if !budgets[i].CreatedAt.Equal(budgets[j].CreatedAt) {
+			return budgets[i].CreatedAt.Before(budgets[j].CreatedAt)
+		}
+		return budgets[i].ID.String() < budgets[j].ID.String()
+	})
+
+	return budgets, nil
+}
+
+// deleteWhere удаляет бюджеты, как ON DELETE CASCADE от пользователя или сервиса в SQL
+func (r *memoryBudgetRepo) deleteWhere(match func(b *entity.Budget) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, b := range r.budgets {
+		if match(b) {
+			delete(r.budgets, id)
+		}
+	}
+}
+
+func cloneBudget(b *entity.Budget) *entity.Budget {
+	clone := *b
+	if b.ServiceID != nil {
+		serviceID := *b.ServiceID
+		clone.ServiceID = &serviceID
+	}
+	if b.Tag != nil {
+		tag := *b.Tag
+		clone.Tag = &tag
+	}
+	return &clone
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+)
+
+type memoryCatalogRepo struct {
+	mu       sync.RWMutex
+	services map[uuid.UUID]*entity.Service
+	// keys — нормализованные названия и алиасы, как name_key и alias_key в SQL
+	keys map[string]uuid.UUID
+	// subscriptions нужны для проверки использования и переименования, блокируются после mu
+	subscriptions *memorySubscriptionRepo
+	// budgets удаляются вместе с сервисом
+	budgets *memoryBudgetRepo
+}
+
+func newMemoryCatalogRepo(subscriptions *memorySubscriptionRepo, budgets *memoryBudgetRepo) *memoryCatalogRepo {
+	return &memoryCatalogRepo{
+		services:      make(map[uuid.UUID]*entity.Service),
+		keys:          make(map[string]uuid.UUID),
+		subscriptions: subscriptions,
+		budgets:       budgets,
+	}
+}
+
+func (r *memoryCatalogRepo) Create(ctx context.Context, service *entity.Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.services[service.ID]; exists {
+		return fmt.Errorf("failed to create service: duplicate id %s", service.ID)
+	}
+	if !r.keysFree(service) {
+		return fmt.Errorf("service already exists")
+	}
+
+	r.services[service.ID] = cloneService(service)
+	r.addKeys(service)
+	return nil
+}
+
+func (r *memoryCatalogRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	service, ok := r.services[id]
+	if !ok {
+		return nil, fmt.Errorf("service not found")
+	}
+
+	return cloneService(service), nil
+}
+
+func (r *memoryCatalogRepo) FindByName(ctx context.Context, name string) (*entity.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.keys[entity.NormalizeServiceName(name)]
+	if !ok {
+		return nil, fmt.Errorf("service not found")
+	}
+
+	return cloneService(r.services[id]), nil
+}
+
+func (r *memoryCatalogRepo) Update(ctx context.Context, service *entity.Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.services[service.ID]
+	if !ok {
+		return fmt.Errorf("service not found")
+	}
+
+	r.removeKeys(old)
+	if !r.keysFree(service) {
+		r.addKeys(old)
+		return fmt.Errorf("service already exists")
+	}
+
+	r.services[service.ID] = cloneService(service)
+	r.addKeys(service)
+
+	r.subscriptions.mu.Lock()
+	defer r.subscriptions.mu.Unlock()
+	for _, s := range r.subscriptions.subscriptions {
+		if s.ServiceID == service.ID {
+			s.ServiceName = service.Name
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryCatalogRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	service, ok := r.services[id]
+	if !ok {
+		return fmt.Errorf("service not found")
+	}
+
+	r.subscriptions.mu.RLock()
+	defer r.subscriptions.mu.RUnlock()
+	for _, s := range r.subscriptions.subscriptions {
+		if s.ServiceID == id {
+			return fmt.Errorf("service is in use")
+		}
+	}
+
+	r.removeKeys(service)
+	delete(r.services, id)
+	r.budgets.deleteWhere(func(b *entity.Budget) bool { return b.ServiceID != nil && *b.ServiceID == id })
+	return nil
+}
+
+// List сортирует по нормализованному названию, как ORDER BY name_key в SQL
+func (r *memoryCatalogRepo) List(ctx context.Context, limit, offset int) ([]*entity.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]*entity.Service, 0, len(r.services))
+	for _, s := range r.services {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return entity.NormalizeServiceName(all[i].Name) < entity.NormalizeServiceName(all[j].Name)
+	})
+
+	var services []*entity.Service
+	for i := offset; i < len(all) && len(services) < limit; i++ {
+		services = append(services, cloneService(all[i]))
+	}
+
+	return services, nil
+}
+
+// resolve возвращает сервис с названием или алиасом name, добавляя его в каталог при отсутствии.
+// Используется при загрузке начальных данных.
+func (r *memoryCatalogRepo) resolve(name string) (*entity.Service, error) {
+	if service, err := r.FindByName(context.Background(), name); err == nil {
+		return service, nil
+	}
+
+	service := &entity.Service{ID: uuid.New(), Name: name}
+	if err := r.Create(context.Background(), service); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+func (r *memoryCatalogRepo) keysFree(service *entity.Service) bool {
+	for _, key := range serviceKeys(service) {
+		if _, taken := r.keys[key]; taken {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *memoryCatalogRepo) addKeys(service *entity.Service) {
+	for _, key := range serviceKeys(service) {
+		r.keys[key] = service.ID
+	}
+}
+
+func (r *memoryCatalogRepo) removeKeys(service *entity.Service) {
+	for _, key := range serviceKeys(service) {
+		delete(r.keys, key)
+	}
+}
+
+func serviceKeys(service *entity.Service) []string {
+	keys := []string{entity.NormalizeServiceName(service.Name)}
+	for _, alias := range service.Aliases {
+		keys = append(keys, entity.NormalizeServiceName(alias))
+	}
+	return keys
+}
+
+func cloneService(s *entity.Service) *entity.Service {
+	clone := *s
+	clone.Aliases = append([]string{}, s.Aliases...)
+	if s.DefaultPrice != nil {
+		price := *s.DefaultPrice
+		clone.DefaultPrice = &price
+	}
+	return &clone
+}
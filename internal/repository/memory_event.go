@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+)
+
+type memoryEventRepo struct {
+	mu     sync.RWMutex
+	events []*entity.Event
+	keys   map[string]bool
+}
+
+func newMemoryEventRepo() *memoryEventRepo {
+	return &memoryEventRepo{keys: make(map[string]bool)}
+}
+
+func (r *memoryEventRepo) Create(ctx context.Context, event *entity.Event) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event.DedupKey != "" {
+		if r.keys[event.DedupKey] {
+			return false, nil
+		}
+		r.keys[event.DedupKey] = true
+	}
+
+	clone := *event
+	r.events = append(r.events, &clone)
+	return true, nil
+}
+
+// ListByUser сортирует сначала новые, как ORDER BY created_at DESC, id в SQL
+func (r *memoryEventRepo) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var all []*entity.Event
+	for _, e := range r.events {
+		if e.UserID == userID {
+			all = append(all, e)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.After(all[j].CreatedAt)
+		}
+		return all[i].ID.String() < all[j].ID.String()
+	})
+
+	events := []*entity.Event{}
+	for i := offset; i < len(all) && len(events) < limit; i++ {
+		clone := *all[i]
+		events = append(events, &clone)
+	}
+
+	return events, nil
+}
+
+// deleteUser удаляет события пользователя, как ON DELETE CASCADE в SQL
+func (r *memoryEventRepo) deleteUser(userID uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.events[:0]
+	for _, e := range r.events {
+		if e.UserID != userID {
+			kept = append(kept, e)
+		}
+	}
+	r.events = kept
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+)
+
+type memoryUserRepo struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]*entity.User
+	// subscriptions нужны для проверки перед удалением, блокируются после mu
+	subscriptions *memorySubscriptionRepo
+	// events и budgets удаляются вместе с пользователем
+	events  *memoryEventRepo
+	budgets *memoryBudgetRepo
+}
+
+func newMemoryUserRepo(subscriptions *memorySubscriptionRepo, events *memoryEventRepo, budgets *memoryBudgetRepo) *memoryUserRepo {
+	return &memoryUserRepo{users: make(map[uuid.UUID]*entity.User), subscriptions: subscriptions, events: events, budgets: budgets}
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[user.ID]; exists || r.emailTaken(user) {
+		return fmt.Errorf("user already exists")
+	}
+
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+func (r *memoryUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return cloneUser(user), nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return fmt.Errorf("user not found")
+	}
+	if r.emailTaken(user) {
+		return fmt.Errorf("user already exists")
+	}
+
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+func (r *memoryUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return fmt.Errorf("user not found")
+	}
+
+	r.subscriptions.mu.RLock()
+	defer r.subscriptions.mu.RUnlock()
+	for _, s := range r.subscriptions.subscriptions {
+		if isParticipant(s, id) {
+			return fmt.Errorf("user has subscriptions")
+		}
+	}
+
+	delete(r.users, id)
+	r.events.deleteUser(id)
+	r.budgets.deleteWhere(func(b *entity.Budget) bool { return b.UserID == id })
+	return nil
+}
+
+// List сортирует по дате создания, как ORDER BY created_at, id в SQL
+func (r *memoryUserRepo) List(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]*entity.User, 0, len(r.users))
+	for _, u := range r.users {
+		all = append(all, u)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.Before(all[j].CreatedAt)
+		}
+		return all[i].ID.String() < all[j].ID.String()
+	})
+
+	var users []*entity.User
+	for i := offset; i < len(all) && len(users) < limit; i++ {
+		users = append(users, cloneUser(all[i]))
+	}
+
+	return users, nil
+}
+
+// emailTaken повторяет уникальный индекс users.email
+func (r *memoryUserRepo) emailTaken(user *entity.User) bool {
+	if user.Email == nil {
+		return false
+	}
+	for _, u := range r.users {
+		if u.ID != user.ID && u.Email != nil && strings.EqualFold(*u.Email, *user.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneUser(u *entity.User) *entity.User {
+	clone := *u
+	if u.Email != nil {
+		email := *u.Email
+		clone.Email = &email
+	}
+	return &clone
+}
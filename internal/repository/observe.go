@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ShekleinAleksey/subscriptions/internal/repository")
+
+// observe выполняет чтение метода репозитория в отдельном спане с текстом SQL,
+// повторяет его при временных ошибках и замеряет общую длительность.
+// sql.ErrNoRows — штатный исход и ошибкой запроса не считается.
+func observe(ctx context.Context, method, query string, fn func(ctx context.Context) error) error {
+	return run(ctx, method, query, isRetryable, fn)
+}
+
+// observeWrite — observe для записи: повторяется, только если запись точно не применена (см. isRetryableWrite)
+func observeWrite(ctx context.Context, method, query string, fn func(ctx context.Context) error) error {
+	return run(ctx, method, query, isRetryableWrite, fn)
+}
+
+func run(ctx context.Context, method, query string, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBOperationName(method),
+			semconv.DBQueryText(query),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := withRetry(ctx, method, retryable, fn)
+
+	metricErr := err
+	if errors.Is(err, sql.ErrNoRows) {
+		metricErr = nil
+	}
+	metrics.ObserveQuery(method, time.Since(start), metricErr)
+
+	if metricErr != nil {
+		span.RecordError(metricErr)
+		span.SetStatus(codes.Error, metricErr.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/sqlite"
+	"github.com/google/uuid"
+)
+
+// newSQLiteTestRepository создает репозитории поверх мигрированной SQLite во временном каталоге
+func newSQLiteTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	migrator, err := sqlite.NewMigrator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	migrator.Close()
+
+	db, err := sqlite.NewDB(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewSQLiteRepository(db)
+}
+
+func parseDay(t *testing.T, value string) time.Time {
+	t.Helper()
+	day, err := time.Parse(entity.DateLayout, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// parityFixture — одинаковые данные для обоих хранилищ; ID фиксированы, чтобы результаты совпадали
+type parityFixture struct {
+	alice, bob       uuid.UUID
+	netflix, spotify uuid.UUID
+	subscriptions    []*entity.Subscription
+}
+
+func newParityFixture(t *testing.T) *parityFixture {
+	f := &parityFixture{
+		alice:   uuid.MustParse("00000000-0000-0000-0000-00000000000a"),
+		bob:     uuid.MustParse("00000000-0000-0000-0000-00000000000b"),
+		netflix: uuid.MustParse("00000000-0000-0000-0001-000000000001"),
+		spotify: uuid.MustParse("00000000-0000-0000-0001-000000000002"),
+	}
+	subscription := func(n int, serviceID uuid.UUID, name string, userID uuid.UUID, start string) *entity.Subscription {
+		startDate := parseDay(t, start)
+		return &entity.Subscription{
+			ID:          uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0002-%012d", n)),
+			ServiceID:   serviceID,
+			ServiceName: name,
+			UserID:      userID,
+			StartDate:   startDate,
+			Prices:      []entity.PricePeriod{{EffectiveFrom: entity.MonthStart(startDate), Price: 100 * n}},
+		}
+	}
+
+	plain := subscription(1, f.netflix, "Netflix", f.alice, "2025-03-15")
+	plain.Tags = []string{"video"}
+	plain.Discounts = []entity.Discount{
+		{
+			ID: uuid.MustParse("00000000-0000-0000-0003-000000000002"), Percent: ptr(33),
+			ValidFrom: parseDay(t, "2025-04-01"), ValidTo: ptr(parseDay(t, "2025-12-01")),
+		},
+		{ID: uuid.MustParse("00000000-0000-0000-0003-000000000003"), Percent: ptr(25), ValidFrom: parseDay(t, "2025-06-01")},
+		{
+			ID: uuid.MustParse("00000000-0000-0000-0003-000000000004"), Amount: ptr(500),
+			ValidFrom: parseDay(t, "2025-07-01"), ValidTo: ptr(parseDay(t, "2025-07-01")),
+		},
+		{ID: uuid.MustParse("00000000-0000-0000-0003-000000000005"), Amount: ptr(20), ValidFrom: parseDay(t, "2025-09-01")},
+	}
+
+	ended := subscription(2, f.netflix, "Netflix", f.alice, "2025-06-01")
+	ended.EndDate = ptr(parseDay(t, "2025-09-30"))
+	ended.Tags = []string{"family", "video"}
+
+	shared := subscription(3, f.spotify, "Spotify", f.alice, "2025-01-10")
+	shared.Participants = []entity.Participant{{UserID: f.bob, Weight: ptr(2)}}
+	shared.Prices = append(shared.Prices, entity.PricePeriod{EffectiveFrom: parseDay(t, "2025-07-01"), Price: 450})
+	shared.Discounts = []entity.Discount{{
+		ID: uuid.MustParse("00000000-0000-0000-0003-000000000001"), Name: "promo", Percent: ptr(10),
+		ValidFrom: parseDay(t, "2025-02-01"), ValidTo: ptr(parseDay(t, "2025-04-30")),
+	}}
+
+	trial := subscription(4, f.spotify, "Spotify", f.bob, "2026-01-01")
+	trial.TrialEndDate = ptr(parseDay(t, "2026-03-01"))
+	trial.BillingDay = ptr(5)
+
+	future := subscription(5, f.netflix, "Netflix", f.bob, "2030-01-01")
+	future.Tags = []string{"video"}
+
+	f.subscriptions = []*entity.Subscription{plain, ended, shared, trial, future}
+	return f
+}
+
+// load сохраняет фикстуру в repo и делает одинаковые изменения после создания
+func (f *parityFixture) load(t *testing.T, repo *Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, user := range []*entity.User{
+		{ID: f.alice, DisplayName: "Alice", Currency: "RUB"},
+		{ID: f.bob, DisplayName: "Bob", Currency: "RUB"},
+	} {
+		if err := repo.UserRepository.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, service := range []*entity.Service{
+		{ID: f.netflix, Name: "Netflix", Aliases: []string{}},
+		{ID: f.spotify, Name: "Spotify", Aliases: []string{}},
+	} {
+		if err := repo.CatalogRepository.Create(ctx, service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, sub := range f.subscriptions {
+		if err := repo.SubscriptionRepository.Create(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subs := repo.SubscriptionRepository
+	plain, shared := f.subscriptions[0].ID, f.subscriptions[2].ID
+	if err := subs.AddTags(ctx, plain, []string{"evening"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := subs.SetPrice(ctx, plain, entity.PricePeriod{EffectiveFrom: parseDay(t, "2026-01-01"), Price: 150}); err != nil {
+		t.Fatal(err)
+	}
+	if err := subs.AddPause(ctx, shared, entity.Pause{
+		ID: uuid.MustParse("00000000-0000-0000-0004-000000000001"), StartDate: parseDay(t, "2025-10-01"), EndDate: ptr(parseDay(t, "2025-11-15")),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	trial := f.subscriptions[3].ID
+	if err := subs.Update(ctx, trial, &entity.UpdateSubscriptionRequest{
+		TrialEndDate: ptr("04-2026"), TrialEnd: ptr(parseDay(t, "2026-04-01")),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := subs.Cancel(ctx, shared, parseDay(t, "2026-06-30"), "too_expensive", nil, parseDay(t, "2026-02-01")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMemoryMatchesSQLite проверяет, что хранилище в памяти отвечает так же, как SQL-хранилище
+// на тех же данных: dev-режим и тесты сервисов не должны расходиться с продакшеном
+func TestMemoryMatchesSQLite(t *testing.T) {
+	memory, err := NewMemoryRepository(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := newSQLiteTestRepository(t)
+
+	f := newParityFixture(t)
+	f.load(t, memory)
+	f.load(t, sql)
+
+	queries := []struct {
+		name string
+		run  func(ctx context.Context, repo SubscriptionRepository) (any, error)
+	}{
+		{"GetByID", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.GetByID(ctx, f.subscriptions[2].ID)
+		}},
+		{"List", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.List(ctx, &entity.ListSubscriptionsRequest{Limit: 10})
+		}},
+		{"List/page", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.List(ctx, &entity.ListSubscriptionsRequest{Limit: 2, Offset: 1})
+		}},
+		{"List/after", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			after := f.subscriptions[0]
+			return repo.List(ctx, &entity.ListSubscriptionsRequest{Limit: 10, After: &entity.ListCursor{StartDate: after.StartDate, ID: after.ID}})
+		}},
+		{"List/participant", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.List(ctx, &entity.ListSubscriptionsRequest{Limit: 10, UserID: &f.bob})
+		}},
+		{"List/tags", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.List(ctx, &entity.ListSubscriptionsRequest{Limit: 10, Tags: []string{"video", "evening"}})
+		}},
+		{"List/status", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.List(ctx, &entity.ListSubscriptionsRequest{
+				Limit: 10, Statuses: []string{entity.StatusTrialing, entity.StatusExpired}, StatusDate: parseDay(t, "2026-02-10"),
+			})
+		}},
+		{"Find", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Find(ctx, &entity.SubscriptionSummaryRequest{})
+		}},
+		{"Find/period", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Find(ctx, &entity.SubscriptionSummaryRequest{StartPeriod: ptr("10-2025"), EndPeriod: ptr("12-2025")})
+		}},
+		{"Find/user and service", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Find(ctx, &entity.SubscriptionSummaryRequest{UserID: &f.bob, ServiceName: ptr("Spotify")})
+		}},
+		{"Find/status", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Find(ctx, &entity.SubscriptionSummaryRequest{
+				Statuses: []string{entity.StatusPaused, entity.StatusTrialing, entity.StatusEndingSoon}, AsOf: ptr("2025-10-20"),
+			})
+		}},
+		{"Find/shared", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Find(ctx, &entity.SubscriptionSummaryRequest{Shared: ptr(false)})
+		}},
+		{"Summary", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Summary(ctx, &entity.SubscriptionSummaryRequest{})
+		}},
+		{"Summary/period", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Summary(ctx, &entity.SubscriptionSummaryRequest{StartPeriod: ptr("01-2025"), EndPeriod: ptr("12-2026")})
+		}},
+		{"Summary/prorate", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Summary(ctx, &entity.SubscriptionSummaryRequest{StartPeriod: ptr("01-2025"), EndPeriod: ptr("12-2026"), Prorate: true})
+		}},
+		{"Summary/status", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Summary(ctx, &entity.SubscriptionSummaryRequest{
+				StartPeriod: ptr("06-2025"), EndPeriod: ptr("06-2026"), Statuses: []string{entity.StatusActive}, AsOf: ptr("2025-10-20"),
+			})
+		}},
+		{"SpendByTag", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.SpendByTag(ctx, &entity.SubscriptionSummaryRequest{StartPeriod: ptr("01-2025"), EndPeriod: ptr("12-2026")})
+		}},
+		{"SpendByTag/tags", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.SpendByTag(ctx, &entity.SubscriptionSummaryRequest{
+				StartPeriod: ptr("01-2025"), EndPeriod: ptr("12-2026"), Tags: []string{"video"}, Prorate: true,
+			})
+		}},
+		{"MonthlySpend", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.MonthlySpend(ctx, &entity.SubscriptionSummaryRequest{StartPeriod: ptr("01-2025"), EndPeriod: ptr("06-2026")},
+				[]uuid.UUID{f.subscriptions[1].ID})
+		}},
+		{"ActiveStats", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.ActiveStats(ctx, parseDay(t, "2025-07-01"))
+		}},
+		{"ActiveStats/paused", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.ActiveStats(ctx, parseDay(t, "2025-10-01"))
+		}},
+		{"Search", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.Search(ctx, &entity.SearchRequest{Query: "netflx", Limit: 10})
+		}},
+		{"TrialsEnding", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.TrialsEnding(ctx, parseDay(t, "2026-01-01"), parseDay(t, "2026-12-01"))
+		}},
+		{"FindOverlapping", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.FindOverlapping(ctx, f.alice, f.netflix, parseDay(t, "2025-07-01"), ptr(parseDay(t, "2025-08-31")))
+		}},
+		{"FindDuplicates", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.FindDuplicates(ctx, nil)
+		}},
+		{"ListTags", func(ctx context.Context, repo SubscriptionRepository) (any, error) {
+			return repo.ListTags(ctx)
+		}},
+	}
+
+	ctx := context.Background()
+	for _, q := range queries {
+		t.Run(q.name, func(t *testing.T) {
+			want := marshal(t, q.run, ctx, sql.SubscriptionRepository)
+			got := marshal(t, q.run, ctx, memory.SubscriptionRepository)
+			if got != want {
+				t.Errorf("memory and SQLite differ\nmemory: %s\nsqlite: %s", got, want)
+			}
+		})
+	}
+}
+
+func marshal(t *testing.T, run func(ctx context.Context, repo SubscriptionRepository) (any, error), ctx context.Context, repo SubscriptionRepository) string {
+	t.Helper()
+	result, err := run(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
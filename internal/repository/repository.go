@@ -4,10 +4,21 @@ import "github.com/jmoiron/sqlx"
 
 type Repository struct {
 	SubscriptionRepository SubscriptionRepository
+	CatalogRepository      CatalogRepository
+	UserRepository         UserRepository
+	EventRepository        EventRepository
+	BudgetRepository       BudgetRepository
+	HealthRepository       HealthRepository
 }
 
-func NewRepository(db *sqlx.DB) *Repository {
+// NewRepository создает репозитории поверх основной базы db и необязательной реплики для чтения
+func NewRepository(db, replica *sqlx.DB) *Repository {
 	return &Repository{
-		SubscriptionRepository: NewSubscriptionRepository(db),
+		SubscriptionRepository: NewSubscriptionRepository(db, replica),
+		CatalogRepository:      NewCatalogRepository(db, replica),
+		UserRepository:         NewUserRepository(db, replica),
+		EventRepository:        NewEventRepository(db, replica),
+		BudgetRepository:       NewBudgetRepository(db, replica),
+		HealthRepository:       NewHealthRepository(db, replica),
 	}
 }
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	retryMaxAttempts = 4
+	retryBaseDelay   = 50 * time.Millisecond
+	retryMaxDelay    = time.Second
+)
+
+// withRetry повторяет fn, пока retryable считает ошибку временной, с экспоненциальной задержкой и джиттером.
+// Ожидание прерывается отменой контекста запроса, тогда возвращается последняя ошибка.
+func withRetry(ctx context.Context, method string, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt == retryMaxAttempts || !retryable(err) {
+			return err
+		}
+
+		wait := delay/2 + rand.N(delay/2+1)
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.String("error", err.Error()),
+		))
+		logger.FromContext(ctx).WithError(err).Warnf("Retrying %s after transient error (attempt %d, wait %s)", method, attempt, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
+
+// isRetryable сообщает, имеет ли смысл повторить чтение:
+// обрыв соединения, конфликт сериализации, взаимная блокировка или перезапуск сервера,
+// а для SQLite — занятая другим писателем база
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"57P01", // admin_shutdown
+			"57P03": // cannot_connect_now
+			return true
+		}
+		return pqErr.Code.Class() == "08" // connection_exception
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		// Младший байт — основной код, старшие уточняют его (SQLITE_BUSY_SNAPSHOT и т.п.)
+		switch sqliteErr.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+			return true
+		}
+		return false
+	}
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		strings.Contains(err.Error(), "connection reset by peer")
+}
+
+// isRetryableWrite сообщает, имеет ли смысл повторить запись. Повторяются только ошибки, после которых
+// сервер точно откатил транзакцию: конфликт сериализации, взаимная блокировка и занятая база SQLite.
+// После обрыва соединения коммит мог успеть пройти, и повтор создал бы дубликат.
+func isRetryableWrite(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		read  bool
+		write bool
+	}{
+		{name: "serialization_failure", err: &pq.Error{Code: "40001"}, read: true, write: true},
+		{name: "deadlock_detected", err: &pq.Error{Code: "40P01"}, read: true, write: true},
+		{name: "admin_shutdown", err: &pq.Error{Code: "57P01"}, read: true},
+		{name: "cannot_connect_now", err: &pq.Error{Code: "57P03"}, read: true},
+		{name: "connection_failure", err: &pq.Error{Code: "08006"}, read: true},
+		{name: "unique_violation", err: &pq.Error{Code: "23505"}},
+		{name: "exclusion_violation", err: &pq.Error{Code: "23P01"}},
+		{name: "обернутая ошибка pq", err: fmt.Errorf("commit: %w", &pq.Error{Code: "40001"}), read: true, write: true},
+		{name: "плохое соединение", err: driver.ErrBadConn, read: true},
+		{name: "обрыв ответа", err: io.ErrUnexpectedEOF, read: true},
+		{name: "сброс соединения", err: fmt.Errorf("read tcp: %w", syscall.ECONNRESET), read: true},
+		{name: "отказ в соединении", err: syscall.ECONNREFUSED, read: true},
+		{name: "сброс соединения текстом", err: errors.New("read: connection reset by peer"), read: true},
+		{name: "отмена запроса", err: context.Canceled},
+		{name: "истек таймаут", err: fmt.Errorf("query: %w", context.DeadlineExceeded)},
+		{name: "прочая ошибка", err: errors.New("syntax error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.read {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.read)
+			}
+			if got := isRetryableWrite(tt.err); got != tt.write {
+				t.Errorf("isRetryableWrite(%v) = %v, want %v", tt.err, got, tt.write)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		retryable func(error) bool
+		calls     int
+		wantErr   bool
+	}{
+		{name: "успех с первого раза", errs: []error{nil}, retryable: isRetryable, calls: 1},
+		{name: "успех после конфликта", errs: []error{&pq.Error{Code: "40001"}, nil}, retryable: isRetryableWrite, calls: 2},
+		{name: "запись не повторяется после обрыва", errs: []error{driver.ErrBadConn}, retryable: isRetryableWrite, calls: 1, wantErr: true},
+		{name: "чтение повторяется после обрыва", errs: []error{driver.ErrBadConn, nil}, retryable: isRetryable, calls: 2},
+		{
+			name:      "попытки заканчиваются",
+			errs:      []error{&pq.Error{Code: "40P01"}, &pq.Error{Code: "40P01"}, &pq.Error{Code: "40P01"}, &pq.Error{Code: "40P01"}},
+			retryable: isRetryableWrite,
+			calls:     retryMaxAttempts,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := withRetry(context.Background(), "test", tt.retryable, func(ctx context.Context) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
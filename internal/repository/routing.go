@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type primaryKey struct{}
+
+// WithPrimary помечает контекст так, что чтения в нем идут в основную базу,
+// а не в реплику, — для запросов, которым нужно увидеть собственные записи.
+// Хранилищам без реплики метка безразлична.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// readFromPrimary сообщает, требует ли контекст чтения из основной базы
+func readFromPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// reader выбирает базу для чтения: реплику, если она настроена
+// и запрос не помечен WithPrimary
+func reader(ctx context.Context, primary, replica *sqlx.DB) *sqlx.DB {
+	if replica == nil || readFromPrimary(ctx) {
+		return primary
+	}
+	return replica
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// NewSQLiteRepository создает репозитории поверх базы SQLite из sqlite.NewDB.
+// Реплик у SQLite нет, все запросы идут в db.
+func NewSQLiteRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		SubscriptionRepository: NewSQLiteSubscriptionRepository(db),
+		CatalogRepository:      NewCatalogRepository(db, nil),
+		UserRepository:         NewUserRepository(db, nil),
+		EventRepository:        NewEventRepository(db, nil),
+		BudgetRepository:       NewBudgetRepository(db, nil),
+		HealthRepository:       NewHealthRepository(db, nil),
+	}
+}
+
+// NewSQLiteSubscriptionRepository переиспользует запросы Postgres-репозитория — они переносимы,
+// а плейсхолдеры $N драйвер SQLite понимает. Отличается работа с датами:
+// SQLite хранит их строками, поэтому они сравниваются через date(), а месяцы перебираются
+// рекурсивным CTE вместо generate_series.
+func NewSQLiteSubscriptionRepository(db *sqlx.DB) SubscriptionRepository {
+	return &subscriptionRepo{db: db, date: func(expr string) string { return "date(" + expr + ")" }, calendar: sqliteCalendar}
+}
@@ -3,13 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	"github.com/sirupsen/logrus"
+	"github.com/lib/pq"
 )
 
 type SubscriptionRepository interface {
@@ -17,63 +20,146 @@ type SubscriptionRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Subscription, error)
 	Update(ctx context.Context, id uuid.UUID, req *entity.UpdateSubscriptionRequest) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, limit, offset int) ([]*entity.Subscription, error)
-	GetSummary(ctx context.Context, req *entity.SubscriptionSummaryRequest) (*entity.SubscriptionSummary, error)
+	List(ctx context.Context, req *entity.ListSubscriptionsRequest) ([]*entity.Subscription, error)
+	AddTags(ctx context.Context, id uuid.UUID, tags []string) error
+	RemoveTag(ctx context.Context, id uuid.UUID, tag string) error
+	ListTags(ctx context.Context) ([]*entity.TagUsage, error)
+	// Find возвращает все подписки под фильтром summary вместе с тегами, ценами и участниками
+	Find(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.Subscription, error)
+	// Summary считает стоимость подписок под фильтром summary за месяцы req.Window
+	Summary(ctx context.Context, req *entity.SubscriptionSummaryRequest) (*entity.SubscriptionSummary, error)
+	// SpendByTag считает то же, что Summary, в разрезе тегов, по возрастанию тега
+	SpendByTag(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.TagSpend, error)
+	// MonthlySpend считает то же, что Summary, по месяцам, сервисам и плательщикам
+	MonthlySpend(ctx context.Context, req *entity.SubscriptionSummaryRequest, cancel []uuid.UUID) ([]*entity.MonthSpend, error)
+	// ActiveStats считает подписки, действующие в месяце month, и их стоимость за месяц по сервисам
+	ActiveStats(ctx context.Context, month time.Time) ([]*entity.ServiceStats, error)
+	// Search возвращает страницу подписок, название сервиса которых похоже на запрос, по убыванию Score
+	Search(ctx context.Context, req *entity.SearchRequest) ([]*entity.SearchResult, error)
+	SetPrice(ctx context.Context, id uuid.UUID, period entity.PricePeriod) error
+	DeletePrice(ctx context.Context, id uuid.UUID, effectiveFrom time.Time) error
+	AddDiscount(ctx context.Context, id uuid.UUID, discount entity.Discount) error
+	DeleteDiscount(ctx context.Context, id, discountID uuid.UUID) error
+	// TrialsEnding возвращает подписки, у которых пробный период заканчивается в месяцах от from до to
+	TrialsEnding(ctx context.Context, from, to time.Time) ([]*entity.Subscription, error)
+	// FindOverlapping возвращает подписки пользователя на сервис, активные хотя бы в одном месяце от start до end
+	FindOverlapping(ctx context.Context, userID, serviceID uuid.UUID, start time.Time, end *time.Time) ([]*entity.Subscription, error)
+	// FindDuplicates возвращает подписки, у которых есть дубликат (см. Subscription.Duplicates); с userID — только его
+	FindDuplicates(ctx context.Context, userID *uuid.UUID) ([]*entity.Subscription, error)
+	// Merge сохраняет период и теги target и удаляет duplicateIDs в одной транзакции
+	Merge(ctx context.Context, target *entity.Subscription, duplicateIDs []uuid.UUID) error
+	// Cancel делает endDate последним днем подписки и сохраняет причину отмены
+	Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, reason string, comment *string, cancelledAt time.Time) error
+	AddPause(ctx context.Context, id uuid.UUID, pause entity.Pause) error
+	// EndPause закрывает паузу последним днем end
+	EndPause(ctx context.Context, id, pauseID uuid.UUID, end time.Time) error
+	DeletePause(ctx context.Context, id, pauseID uuid.UUID) error
 }
 
+// subscriptionColumns — порядок столбцов, в котором их читает scanSubscription
+const subscriptionColumns = `id, service_id, service_name, user_id, start_date, end_date, trial_end_date, billing_day,
+    cancel_reason, cancel_comment, cancelled_at`
+
 type subscriptionRepo struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	replica *sqlx.DB
+	// date оборачивает выражение для сравнения дат: в Postgres это значения DATE,
+	// в SQLite — строки, которые сравниваются через date()
+	date func(expr string) string
+	// trgm — в базе есть pg_trgm; без него поиск по названию ранжируется в Go (см. rankSearch)
+	trgm bool
+	// calendar — месяцы и дни в диалекте базы для расчета стоимости по месяцам
+	calendar calendar
 }
 
-func NewSubscriptionRepository(db *sqlx.DB) SubscriptionRepository {
-	return &subscriptionRepo{db: db}
+// NewSubscriptionRepository создает репозиторий; replica может быть nil,
+// тогда все запросы идут в основную базу
+func NewSubscriptionRepository(db, replica *sqlx.DB) SubscriptionRepository {
+	return &subscriptionRepo{
+		db: db, replica: replica, date: func(expr string) string { return expr }, trgm: true, calendar: postgresCalendar,
+	}
 }
 
 func (r *subscriptionRepo) Create(ctx context.Context, subscription *entity.Subscription) error {
 	query := `
-        INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO subscriptions (id, service_id, service_name, user_id, start_date, end_date, trial_end_date, billing_day, exclusive)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
     `
 
-	_, err := r.db.ExecContext(ctx, query,
-		subscription.ID,
-		subscription.ServiceName,
-		subscription.Price,
-		subscription.UserID,
-		subscription.StartDate,
-		subscription.EndDate,
-	)
+	err := observeWrite(ctx, "SubscriptionRepository.Create", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		_, err = tx.ExecContext(ctx, query,
+			subscription.ID,
+			subscription.ServiceID,
+			subscription.ServiceName,
+			subscription.UserID,
+			subscription.StartDate,
+			subscription.EndDate,
+			subscription.TrialEndDate,
+			subscription.BillingDay,
+			subscription.Exclusive,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := insertTags(ctx, tx, subscription.ID, subscription.Tags); err != nil {
+			return err
+		}
+		for _, period := range subscription.Prices {
+			if err := upsertPrice(ctx, tx, subscription.ID, period); err != nil {
+				return err
+			}
+		}
+		for _, discount := range subscription.Discounts {
+			if err := insertDiscount(ctx, tx, subscription.ID, discount); err != nil {
+				return err
+			}
+		}
+		if err := insertParticipants(ctx, tx, subscription.ID, subscription.Participants); err != nil {
+			return err
+		}
 
+		return tx.Commit()
+	})
+
+	if overlap := exclusionError(err); overlap != nil {
+		return overlap
+	}
 	if err != nil {
-		logrus.Fatalf("Error creating subscription: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Failed to create subscription")
 		return fmt.Errorf("failed to create subscription: %w", err)
 	}
 
-	logrus.Info("Subscription created successfully: %s", subscription.ID)
+	logger.FromContext(ctx).Infof("Subscription created successfully: %s", subscription.ID)
 	return nil
 }
 
 func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Subscription, error) {
 	query := `
-        SELECT *
+        SELECT ` + subscriptionColumns + `
         FROM subscriptions WHERE id = $1
     `
 
 	var subscription entity.Subscription
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&subscription.ID,
-		&subscription.ServiceName,
-		&subscription.Price,
-		&subscription.UserID,
-		&subscription.StartDate,
-		&subscription.EndDate,
-	)
+	err := observe(ctx, "SubscriptionRepository.GetByID", query, func(ctx context.Context) error {
+		db := reader(ctx, r.db, r.replica)
+		if err := scanSubscription(db.QueryRowContext(ctx, query, id), &subscription); err != nil {
+			return err
+		}
+		return loadRelations(ctx, db, []*entity.Subscription{&subscription})
+	})
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("subscription not found")
 	}
 	if err != nil {
-		logrus.Fatalf("Error getting subscription by ID: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Failed to get subscription by ID")
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
@@ -81,162 +167,1141 @@ func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.S
 }
 
 func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, req *entity.UpdateSubscriptionRequest) error {
-	query := "UPDATE subscriptions SET"
+	var sets []string
 	params := []interface{}{}
-	paramCount := 1
 
-	if req.ServiceName != nil {
-		query += fmt.Sprintf(" service_name = $%d", paramCount)
-		params = append(params, *req.ServiceName)
-		paramCount++
+	if req.ServiceID != nil {
+		params = append(params, *req.ServiceID)
+		sets = append(sets, fmt.Sprintf("service_id = $%d", len(params)))
 	}
 
-	if req.Price != nil {
-		query += fmt.Sprintf(", price = $%d", paramCount)
-		params = append(params, *req.Price)
-		paramCount++
+	if req.ServiceName != nil {
+		params = append(params, *req.ServiceName)
+		sets = append(sets, fmt.Sprintf("service_name = $%d", len(params)))
 	}
 
 	if req.StartDate != nil {
-		startDate, err := time.Parse("01-2006", *req.StartDate)
+		startDate, err := entity.ParseDate(*req.StartDate)
 		if err != nil {
 			return fmt.Errorf("invalid start_date format: %w", err)
 		}
-		query += fmt.Sprintf(", start_date = $%d", paramCount)
 		params = append(params, startDate)
-		paramCount++
+		sets = append(sets, fmt.Sprintf("start_date = $%d", len(params)))
 	}
 
 	if req.EndDate != nil {
 		if *req.EndDate == "" {
-			query += fmt.Sprintf(", end_date = $%d", paramCount)
 			params = append(params, nil)
 		} else {
-			endDate, err := time.Parse("01-2006", *req.EndDate)
+			endDate, err := entity.ParseEndDate(*req.EndDate)
 			if err != nil {
 				return fmt.Errorf("invalid end_date format: %w", err)
 			}
-			query += fmt.Sprintf(", end_date = $%d", paramCount)
 			params = append(params, endDate)
 		}
-		paramCount++
+		sets = append(sets, fmt.Sprintf("end_date = $%d", len(params)))
+	}
+
+	if req.TrialEndDate != nil {
+		params = append(params, req.TrialEnd)
+		sets = append(sets, fmt.Sprintf("trial_end_date = $%d", len(params)))
+	}
+
+	if req.BillingDay != nil {
+		params = append(params, *req.BillingDay)
+		sets = append(sets, fmt.Sprintf("billing_day = $%d", len(params)))
+	}
+
+	if req.Exclusive != nil {
+		params = append(params, *req.Exclusive)
+		sets = append(sets, fmt.Sprintf("exclusive = $%d", len(params)))
+	}
+
+	if len(sets) == 0 && req.Price == nil && req.Tags == nil && req.Participants == nil {
+		return fmt.Errorf("no fields to update")
 	}
 
-	query += " WHERE id = $" + fmt.Sprint(paramCount)
 	params = append(params, id)
+	query := fmt.Sprintf("UPDATE subscriptions SET %s WHERE id = $%d", strings.Join(sets, ", "), len(params))
+	if len(sets) == 0 {
+		// Меняются только цена, теги или участники — проверяем лишь, что подписка существует
+		query = `SELECT COUNT(*) FROM subscriptions WHERE id = $1`
+	}
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.Update", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if len(sets) == 0 {
+			err = tx.GetContext(ctx, &rowsAffected, query, id)
+		} else {
+			var result sql.Result
+			if result, err = tx.ExecContext(ctx, query, params...); err == nil {
+				rowsAffected, _ = result.RowsAffected()
+			}
+		}
+		if err != nil || rowsAffected == 0 {
+			return err
+		}
 
-	result, err := r.db.ExecContext(ctx, query, params...)
+		if req.Price != nil {
+			if err := upsertPrice(ctx, tx, id, entity.PricePeriod{EffectiveFrom: req.PriceFrom, Price: *req.Price}); err != nil {
+				return err
+			}
+		}
+
+		if req.Tags != nil {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_tags WHERE subscription_id = $1`, id); err != nil {
+				return err
+			}
+			if err := insertTags(ctx, tx, id, *req.Tags); err != nil {
+				return err
+			}
+		}
+
+		if req.Participants != nil {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_participants WHERE subscription_id = $1`, id); err != nil {
+				return err
+			}
+			if err := insertParticipants(ctx, tx, id, *req.Participants); err != nil {
+				return err
+			}
+		}
+
+		return tx.Commit()
+	})
+	if overlap := exclusionError(err); overlap != nil {
+		return overlap
+	}
 	if err != nil {
-		logrus.Fatalf("Error updating subscription: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Failed to update subscription")
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
 		return fmt.Errorf("subscription not found")
 	}
 
-	logrus.Info("Subscription updated successfully: %s", id)
+	logger.FromContext(ctx).Infof("Subscription updated successfully: %s", id)
 	return nil
 }
 
 func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	query := "DELETE FROM subscriptions WHERE id = $1"
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.Delete", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
 	if err != nil {
-		logrus.Fatalf("Error deleting subscription: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Failed to delete subscription")
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
 		return fmt.Errorf("subscription not found")
 	}
 
-	logrus.Info("Subscription deleted successfully: %s", id)
+	logger.FromContext(ctx).Infof("Subscription deleted successfully: %s", id)
 	return nil
 }
 
-func (r *subscriptionRepo) List(ctx context.Context, limit, offset int) ([]*entity.Subscription, error) {
-	query := `
-        SELECT *
-        FROM subscriptions 
-        LIMIT $1 OFFSET $2
-    `
+func (r *subscriptionRepo) List(ctx context.Context, req *entity.ListSubscriptionsRequest) ([]*entity.Subscription, error) {
+	var where string
+	var params []interface{}
+	if req.UserID != nil {
+		params = append(params, *req.UserID)
+		where = userFilter(1)
+	}
+	tagWhere, tagParams := tagFilter(req.Tags, len(params)+1)
+	where += tagWhere
+	params = append(params, tagParams...)
+	if len(req.Statuses) > 0 {
+		statusWhere, statusParams := r.statusFilter(req.Statuses, req.StatusDate, len(params)+1)
+		where += statusWhere
+		params = append(params, statusParams...)
+	}
+	if req.After != nil {
+		params = append(params, req.After.StartDate, req.After.ID)
+		where += fmt.Sprintf(" AND (%[1]s > %[2]s OR %[1]s = %[2]s AND id > $%[3]d)",
+			r.date("start_date"), r.date(fmt.Sprintf("$%d", len(params)-1)), len(params))
+	}
+
+	// Без порядка Postgres вправе вернуть строки соседних страниц в любом порядке,
+	// и постраничный обход повторял бы или пропускал подписки
+	query := fmt.Sprintf(`
+        SELECT `+subscriptionColumns+`
+        FROM subscriptions WHERE 1=1%s
+        ORDER BY start_date, id
+        LIMIT $%d OFFSET $%d
+    `, where, len(params)+1, len(params)+2)
+	params = append(params, req.Limit, req.Offset)
+
+	var subscriptions []*entity.Subscription
+	err := observe(ctx, "SubscriptionRepository.List", query, func(ctx context.Context) error {
+		db := reader(ctx, r.db, r.replica)
+		rows, err := db.QueryContext(ctx, query, params...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+		for rows.Next() {
+			var subscription entity.Subscription
+			if err := scanSubscription(rows, &subscription); err != nil {
+				return fmt.Errorf("failed to scan subscription: %w", err)
+			}
+			subscriptions = append(subscriptions, &subscription)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return loadRelations(ctx, db, subscriptions)
+	})
 	if err != nil {
-		logrus.Fatalf("Error listing subscriptions: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Failed to list subscriptions")
 		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 	}
-	defer rows.Close()
+
+	logger.FromContext(ctx).Infof("Listed %d subscriptions", len(subscriptions))
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepo) Find(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.Subscription, error) {
+	where, params, err := r.summaryFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE 1=1` + where + ` ORDER BY start_date, id`
 
 	var subscriptions []*entity.Subscription
-	for rows.Next() {
-		var subscription entity.Subscription
-		err := rows.Scan(
-			&subscription.ID,
-			&subscription.ServiceName,
-			&subscription.Price,
-			&subscription.UserID,
-			&subscription.StartDate,
-			&subscription.EndDate,
-		)
+	err = observe(ctx, "SubscriptionRepository.Find", query, func(ctx context.Context) error {
+		subscriptions, err = selectSubscriptions(ctx, reader(ctx, r.db, r.replica), query, params...)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to find subscriptions")
+		return nil, fmt.Errorf("failed to find subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepo) TrialsEnding(ctx context.Context, from, to time.Time) ([]*entity.Subscription, error) {
+	query := fmt.Sprintf(`
+        SELECT `+subscriptionColumns+`
+        FROM subscriptions
+        WHERE trial_end_date IS NOT NULL AND %s >= %s AND %s <= %s
+        ORDER BY trial_end_date, id
+    `, r.date("trial_end_date"), r.date("$1"), r.date("trial_end_date"), r.date("$2"))
+
+	var subscriptions []*entity.Subscription
+	err := observe(ctx, "SubscriptionRepository.TrialsEnding", query, func(ctx context.Context) (err error) {
+		subscriptions, err = selectSubscriptions(ctx, reader(ctx, r.db, r.replica), query, from, to)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to find ending trials")
+		return nil, fmt.Errorf("failed to find ending trials: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepo) FindOverlapping(ctx context.Context, userID, serviceID uuid.UUID, start time.Time, end *time.Time) ([]*entity.Subscription, error) {
+	where := fmt.Sprintf(" AND (end_date IS NULL OR %s >= %s)", r.date("end_date"), r.date("$3"))
+	params := []interface{}{userID, serviceID, start}
+	if end != nil {
+		where += fmt.Sprintf(" AND %s <= %s", r.date("start_date"), r.date("$4"))
+		params = append(params, *end)
+	}
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions
+        WHERE user_id = $1 AND service_id = $2` + where + ` ORDER BY start_date, id`
+
+	var subscriptions []*entity.Subscription
+	err := observe(ctx, "SubscriptionRepository.FindOverlapping", query, func(ctx context.Context) (err error) {
+		subscriptions, err = selectSubscriptions(ctx, reader(ctx, r.db, r.replica), query, params...)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to find overlapping subscriptions")
+		return nil, fmt.Errorf("failed to find overlapping subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepo) FindDuplicates(ctx context.Context, userID *uuid.UUID) ([]*entity.Subscription, error) {
+	var where string
+	var params []interface{}
+	if userID != nil {
+		where = " AND user_id = $1"
+		params = append(params, *userID)
+	}
+	query := fmt.Sprintf(`
+        SELECT `+subscriptionColumns+`
+        FROM subscriptions s
+        WHERE EXISTS (
+            SELECT 1 FROM subscriptions d
+            WHERE d.user_id = s.user_id AND d.service_id = s.service_id AND d.id <> s.id
+                AND (d.end_date IS NULL OR %s >= %s)
+                AND (s.end_date IS NULL OR %s <= %s))%s
+        ORDER BY user_id, service_id, start_date, id
+    `, r.date("d.end_date"), r.date("s.start_date"), r.date("d.start_date"), r.date("s.end_date"), where)
+
+	var subscriptions []*entity.Subscription
+	err := observe(ctx, "SubscriptionRepository.FindDuplicates", query, func(ctx context.Context) (err error) {
+		subscriptions, err = selectSubscriptions(ctx, reader(ctx, r.db, r.replica), query, params...)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to find duplicate subscriptions")
+		return nil, fmt.Errorf("failed to find duplicate subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// Search ищет по триграммам pg_trgm: название похоже на запрос (оператор %) или содержит его.
+// Оба условия обслуживает GIN-индекс idx_subscriptions_service_name_trgm.
+func (r *subscriptionRepo) Search(ctx context.Context, req *entity.SearchRequest) ([]*entity.SearchResult, error) {
+	if !r.trgm {
+		return r.searchWithoutTrgm(ctx, req)
+	}
+
+	params := []interface{}{req.Query, "%" + escapeLike(req.Query) + "%"}
+	var where string
+	if req.UserID != nil {
+		params = append(params, *req.UserID)
+		where = userFilter(len(params))
+	}
+	params = append(params, req.Limit, req.Offset)
+
+	query := fmt.Sprintf(`
+        SELECT similarity(service_name, $1) AS score, `+subscriptionColumns+`
+        FROM subscriptions
+        WHERE (service_name %% $1 OR service_name ILIKE $2)%s
+        ORDER BY score DESC, service_name, id
+        LIMIT $%d OFFSET $%d
+    `, where, len(params)-1, len(params))
+
+	var results []*entity.SearchResult
+	err := observe(ctx, "SubscriptionRepository.Search", query, func(ctx context.Context) error {
+		db := reader(ctx, r.db, r.replica)
+		rows, err := db.QueryContext(ctx, query, params...)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+			return err
 		}
-		subscriptions = append(subscriptions, &subscription)
+		defer rows.Close()
+
+		var subscriptions []*entity.Subscription
+		for rows.Next() {
+			result := &entity.SearchResult{Subscription: &entity.Subscription{}}
+			if err := scanSubscription(scoredRow{rows, &result.Score}, result.Subscription); err != nil {
+				return fmt.Errorf("failed to scan subscription: %w", err)
+			}
+			results = append(results, result)
+			subscriptions = append(subscriptions, result.Subscription)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return loadRelations(ctx, db, subscriptions)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to search subscriptions")
+		return nil, fmt.Errorf("failed to search subscriptions: %w", err)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating subscriptions: %w", err)
+	return results, nil
+}
+
+// searchWithoutTrgm выбирает подписки пользователя целиком и ранжирует их в Go так же, как pg_trgm
+func (r *subscriptionRepo) searchWithoutTrgm(ctx context.Context, req *entity.SearchRequest) ([]*entity.SearchResult, error) {
+	var where string
+	var params []interface{}
+	if req.UserID != nil {
+		params = append(params, *req.UserID)
+		where = userFilter(1)
 	}
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE 1=1` + where
 
-	logrus.Info("Listed %d subscriptions", len(subscriptions))
-	return subscriptions, nil
+	var subscriptions []*entity.Subscription
+	err := observe(ctx, "SubscriptionRepository.Search", query, func(ctx context.Context) (err error) {
+		subscriptions, err = selectSubscriptions(ctx, reader(ctx, r.db, r.replica), query, params...)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to search subscriptions")
+		return nil, fmt.Errorf("failed to search subscriptions: %w", err)
+	}
+
+	return rankSearch(subscriptions, req), nil
+}
+
+// Merge переносит на target объединенный период и теги дубликатов; цены, скидки и участники
+// дубликатов удаляются вместе с ними каскадом
+func (r *subscriptionRepo) Merge(ctx context.Context, target *entity.Subscription, duplicateIDs []uuid.UUID) error {
+	query := `UPDATE subscriptions SET start_date = $1, end_date = $2 WHERE id = $3`
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.Merge", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		// Дубликаты удаляются до расширения периода target, иначе он пересекся бы с ними
+		// и нарушил ограничение subscriptions_no_overlap
+		for _, id := range duplicateIDs {
+			result, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE id = $1`, id)
+			if err != nil {
+				return err
+			}
+			if deleted, _ := result.RowsAffected(); deleted == 0 {
+				rowsAffected = 0
+				return nil
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, query, target.StartDate, target.EndDate, target.ID)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, _ = result.RowsAffected(); rowsAffected == 0 {
+			return nil
+		}
+
+		if err := insertTags(ctx, tx, target.ID, target.Tags); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+	if overlap := exclusionError(err); overlap != nil {
+		return overlap
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to merge subscriptions")
+		return fmt.Errorf("failed to merge subscriptions: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	logger.FromContext(ctx).Infof("Merged %d subscriptions into %s", len(duplicateIDs), target.ID)
+	return nil
+}
+
+// exclusionError возвращает ошибку пересечения, если запись нарушила ограничение subscriptions_no_overlap:
+// параллельный запрос успел сохранить пересекающуюся подписку после проверки в сервисе. Иначе nil
+func exclusionError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23P01" { // exclusion_violation
+		return fmt.Errorf("subscription overlaps with another subscription: same user, service and period")
+	}
+	return nil
+}
+
+// SetPrice задает цену с месяца period.EffectiveFrom, заменяя цену, уже назначенную на этот месяц
+func (r *subscriptionRepo) SetPrice(ctx context.Context, id uuid.UUID, period entity.PricePeriod) error {
+	query := `SELECT COUNT(*) FROM subscriptions WHERE id = $1`
+
+	var exists int
+	err := observeWrite(ctx, "SubscriptionRepository.SetPrice", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := tx.GetContext(ctx, &exists, query, id); err != nil || exists == 0 {
+			return err
+		}
+		if err := upsertPrice(ctx, tx, id, period); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to set subscription price")
+		return fmt.Errorf("failed to set subscription price: %w", err)
+	}
+
+	if exists == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	return nil
+}
+
+// DeletePrice отменяет изменение цены с месяца effectiveFrom
+func (r *subscriptionRepo) DeletePrice(ctx context.Context, id uuid.UUID, effectiveFrom time.Time) error {
+	query := fmt.Sprintf(`DELETE FROM subscription_prices WHERE subscription_id = $1 AND %s = %s`,
+		r.date("effective_from"), r.date("$2"))
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.DeletePrice", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, id, effectiveFrom)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to delete subscription price")
+		return fmt.Errorf("failed to delete subscription price: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("price change not found")
+	}
+
+	return nil
+}
+
+// AddDiscount добавляет скидку к подписке
+func (r *subscriptionRepo) AddDiscount(ctx context.Context, id uuid.UUID, discount entity.Discount) error {
+	query := `SELECT COUNT(*) FROM subscriptions WHERE id = $1`
+
+	var exists int
+	err := observeWrite(ctx, "SubscriptionRepository.AddDiscount", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := tx.GetContext(ctx, &exists, query, id); err != nil || exists == 0 {
+			return err
+		}
+		if err := insertDiscount(ctx, tx, id, discount); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to add subscription discount")
+		return fmt.Errorf("failed to add subscription discount: %w", err)
+	}
+
+	if exists == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	return nil
 }
 
-func (r *subscriptionRepo) GetSummary(ctx context.Context, req *entity.SubscriptionSummaryRequest) (*entity.SubscriptionSummary, error) {
-	query := `SELECT COALESCE(SUM(price), 0), COUNT(*) FROM subscriptions WHERE 1=1`
+func (r *subscriptionRepo) DeleteDiscount(ctx context.Context, id, discountID uuid.UUID) error {
+	query := `DELETE FROM subscription_discounts WHERE subscription_id = $1 AND id = $2`
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.DeleteDiscount", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, id, discountID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to delete subscription discount")
+		return fmt.Errorf("failed to delete subscription discount: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("discount not found")
+	}
+
+	return nil
+}
+
+func (r *subscriptionRepo) Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, reason string, comment *string, cancelledAt time.Time) error {
+	query := `
+        UPDATE subscriptions SET end_date = $1, cancel_reason = $2, cancel_comment = $3, cancelled_at = $4
+        WHERE id = $5
+    `
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.Cancel", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, endDate, reason, comment, cancelledAt, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to cancel subscription")
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	logger.FromContext(ctx).Infof("Subscription cancelled: %s", id)
+	return nil
+}
+
+func (r *subscriptionRepo) AddPause(ctx context.Context, id uuid.UUID, pause entity.Pause) error {
+	query := `INSERT INTO subscription_pauses (id, subscription_id, start_date, end_date) VALUES ($1, $2, $3, $4)`
+
+	err := observeWrite(ctx, "SubscriptionRepository.AddPause", query, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, query, pause.ID, id, pause.StartDate, pause.EndDate)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to pause subscription")
+		return fmt.Errorf("failed to pause subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *subscriptionRepo) EndPause(ctx context.Context, id, pauseID uuid.UUID, end time.Time) error {
+	query := `UPDATE subscription_pauses SET end_date = $1 WHERE subscription_id = $2 AND id = $3`
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.EndPause", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, end, id, pauseID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to resume subscription")
+		return fmt.Errorf("failed to resume subscription: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pause not found")
+	}
+
+	return nil
+}
+
+func (r *subscriptionRepo) DeletePause(ctx context.Context, id, pauseID uuid.UUID) error {
+	query := `DELETE FROM subscription_pauses WHERE subscription_id = $1 AND id = $2`
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "SubscriptionRepository.DeletePause", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, id, pauseID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to delete subscription pause")
+		return fmt.Errorf("failed to delete subscription pause: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pause not found")
+	}
+
+	return nil
+}
+
+// AddTags добавляет теги к подписке; уже существующие пропускаются
+func (r *subscriptionRepo) AddTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	query := `SELECT COUNT(*) FROM subscriptions WHERE id = $1`
+
+	var found int
+	err := observeWrite(ctx, "SubscriptionRepository.AddTags", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := tx.GetContext(ctx, &found, query, id); err != nil || found == 0 {
+			return err
+		}
+		if err := insertTags(ctx, tx, id, tags); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to add subscription tags")
+		return fmt.Errorf("failed to add tags: %w", err)
+	}
+
+	if found == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	return nil
+}
+
+func (r *subscriptionRepo) RemoveTag(ctx context.Context, id uuid.UUID, tag string) error {
+	query := `DELETE FROM subscription_tags WHERE subscription_id = $1 AND tag = $2`
+
+	var rowsAffected int64
+	var found int
+	err := observeWrite(ctx, "SubscriptionRepository.RemoveTag", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query, id, tag)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, _ = result.RowsAffected(); rowsAffected > 0 {
+			return nil
+		}
+		return r.db.GetContext(ctx, &found, `SELECT COUNT(*) FROM subscriptions WHERE id = $1`, id)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to remove subscription tag")
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	if rowsAffected == 0 && found == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// ListTags возвращает все теги с числом подписок
+func (r *subscriptionRepo) ListTags(ctx context.Context) ([]*entity.TagUsage, error) {
+	query := `SELECT tag, COUNT(*) AS count FROM subscription_tags GROUP BY tag ORDER BY tag`
+
+	tags := []*entity.TagUsage{}
+	err := observe(ctx, "SubscriptionRepository.ListTags", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).SelectContext(ctx, &tags, query)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to list tags")
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// summaryFilter строит условия summary, начиная с параметра $1.
+// Если периоды не переданы, используем все время. Статус проверяется точно, как StatusAt.
+func (r *subscriptionRepo) summaryFilter(req *entity.SubscriptionSummaryRequest) (string, []interface{}, error) {
+	var where string
 	params := []interface{}{}
 	paramCount := 1
 
-	// Если периоды не переданы, используем все время
 	if req.StartPeriod != nil && req.EndPeriod != nil {
 		startPeriod, err := time.Parse("01-2006", *req.StartPeriod)
 		if err != nil {
-			return nil, fmt.Errorf("invalid start_period format: %w", err)
+			return "", nil, fmt.Errorf("invalid start_period format: %w", err)
 		}
 
 		endPeriod, err := time.Parse("01-2006", *req.EndPeriod)
 		if err != nil {
-			return nil, fmt.Errorf("invalid end_period format: %w", err)
+			return "", nil, fmt.Errorf("invalid end_period format: %w", err)
 		}
 
-		query += fmt.Sprintf(" AND start_date <= $%d AND (end_date IS NULL OR end_date >= $%d)", paramCount, paramCount+1)
-		params = append(params, endPeriod, startPeriod)
+		where += fmt.Sprintf(" AND %s <= %s AND (end_date IS NULL OR %s >= %s)",
+			r.date("start_date"), r.date(fmt.Sprintf("$%d", paramCount)),
+			r.date("end_date"), r.date(fmt.Sprintf("$%d", paramCount+1)))
+		params = append(params, entity.MonthEnd(endPeriod), startPeriod)
 		paramCount += 2
 	}
 
 	if req.UserID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", paramCount)
+		where += userFilter(paramCount)
 		params = append(params, *req.UserID)
 		paramCount++
 	}
 
 	if req.ServiceName != nil {
-		query += fmt.Sprintf(" AND service_name = $%d", paramCount)
+		where += fmt.Sprintf(" AND service_name = $%d", paramCount)
 		params = append(params, *req.ServiceName)
 		paramCount++
 	}
 
-	var summary entity.SubscriptionSummary
-	err := r.db.QueryRowContext(ctx, query, params...).Scan(&summary.TotalCost, &summary.Count)
+	if req.Shared != nil {
+		not := ""
+		if !*req.Shared {
+			not = "NOT "
+		}
+		where += " AND " + not + "EXISTS (SELECT 1 FROM subscription_participants WHERE subscription_id = subscriptions.id)"
+	}
+
+	tags, tagParams := tagFilter(req.Tags, paramCount)
+	where += tags
+	params = append(params, tagParams...)
+	paramCount += len(tagParams)
+
+	if len(req.Statuses) > 0 {
+		status, statusParams := r.statusAt(req.AsOfDay(time.Now()), paramCount)
+		params = append(params, statusParams...)
+		placeholders := make([]string, len(req.Statuses))
+		for i, st := range req.Statuses {
+			params = append(params, st)
+			placeholders[i] = fmt.Sprintf("$%d", paramCount+len(statusParams)+i)
+		}
+		where += fmt.Sprintf(" AND %s IN (%s)", status, strings.Join(placeholders, ", "))
+	}
+
+	return where, params, nil
+}
+
+// statusAt возвращает выражение со статусом подписки на день day, как Subscription.StatusAt;
+// параметры нумеруются с first
+func (r *subscriptionRepo) statusAt(day time.Time, first int) (string, []interface{}) {
+	dayParam := r.date(fmt.Sprintf("$%d", first))
+	return fmt.Sprintf(`CASE
+            WHEN %[1]s > %[2]s THEN '`+entity.StatusScheduled+`'
+            WHEN end_date IS NOT NULL AND %[3]s < %[2]s THEN '`+entity.StatusExpired+`'
+            WHEN EXISTS (SELECT 1 FROM subscription_pauses p WHERE p.subscription_id = subscriptions.id
+                AND %[4]s <= %[2]s AND (p.end_date IS NULL OR %[5]s >= %[2]s)) THEN '`+entity.StatusPaused+`'
+            WHEN trial_end_date IS NOT NULL AND %[6]s >= %[7]s THEN '`+entity.StatusTrialing+`'
+            WHEN end_date IS NOT NULL AND %[3]s <= %[8]s THEN '`+entity.StatusEndingSoon+`'
+            ELSE '`+entity.StatusActive+`' END`,
+			r.date("start_date"), dayParam, r.date("end_date"), r.date("p.start_date"), r.date("p.end_date"),
+			r.date("trial_end_date"), r.date(fmt.Sprintf("$%d", first+1)), r.date(fmt.Sprintf("$%d", first+2))),
+		[]interface{}{day, entity.MonthStart(day), day.AddDate(0, 0, entity.EndingSoonDays)}
+}
+
+// userFilter оставляет подписки, которые пользователь оплачивает или в которых участвует
+func userFilter(param int) string {
+	return fmt.Sprintf(` AND (user_id = $%[1]d OR subscriptions.id IN (
+            SELECT subscription_id FROM subscription_participants WHERE user_id = $%[1]d))`, param)
+}
+
+// tagFilter оставляет подписки, у которых есть все теги tags; параметры нумеруются с first
+func tagFilter(tags []string, first int) (string, []interface{}) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+
+	placeholders := make([]string, len(tags))
+	params := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		placeholders[i] = fmt.Sprintf("$%d", first+i)
+		params[i] = tag
+	}
+
+	return fmt.Sprintf(` AND subscriptions.id IN (
+            SELECT subscription_id FROM subscription_tags
+            WHERE tag IN (%s) GROUP BY subscription_id HAVING COUNT(*) = %d)`,
+		strings.Join(placeholders, ", "), len(tags)), params
+}
+
+// statusFilter оставляет подписки, даты которых допускают на день day один из statuses,
+// как Subscription.MayHaveStatus; параметры нумеруются с first
+func (r *subscriptionRepo) statusFilter(statuses []string, day time.Time, first int) (string, []interface{}) {
+	params := []interface{}{day}
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return r.date(fmt.Sprintf("$%d", first+len(params)-1))
+	}
+	dayParam := r.date(fmt.Sprintf("$%d", first))
+	current := fmt.Sprintf("%s <= %s AND (end_date IS NULL OR %s >= %s)",
+		r.date("start_date"), dayParam, r.date("end_date"), dayParam)
+
+	conditions := make([]string, len(statuses))
+	for i, status := range statuses {
+		switch status {
+		case entity.StatusScheduled:
+			conditions[i] = fmt.Sprintf("%s > %s", r.date("start_date"), dayParam)
+		case entity.StatusExpired:
+			conditions[i] = fmt.Sprintf("%s < %s", r.date("end_date"), dayParam)
+		case entity.StatusTrialing:
+			conditions[i] = fmt.Sprintf("%s AND %s >= %s", current, r.date("trial_end_date"), param(entity.MonthStart(day)))
+		case entity.StatusEndingSoon:
+			conditions[i] = fmt.Sprintf("%s AND %s <= %s", current, r.date("end_date"), param(day.AddDate(0, 0, entity.EndingSoonDays)))
+		default:
+			conditions[i] = current
+		}
+	}
+
+	return " AND (" + strings.Join(conditions, " OR ") + ")", params
+}
+
+func insertTags(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO subscription_tags (subscription_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING`, id, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertParticipants(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, participants []entity.Participant) error {
+	for _, p := range participants {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO subscription_participants (subscription_id, user_id, weight, amount) VALUES ($1, $2, $3, $4)`,
+			id, p.UserID, p.Weight, p.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertPrice(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, period entity.PricePeriod) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO subscription_prices (subscription_id, effective_from, price) VALUES ($1, $2, $3)
+        ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = excluded.price`,
+		id, period.EffectiveFrom, period.Price)
+	return err
+}
+
+func insertDiscount(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, d entity.Discount) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO subscription_discounts (id, subscription_id, name, percent, amount, valid_from, valid_to)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		d.ID, id, d.Name, d.Percent, d.Amount, d.ValidFrom, d.ValidTo)
+	return err
+}
+
+// loadRelations заполняет теги, цены, скидки, участников и паузы подписок,
+// а затем вычисляемые поля на текущий момент
+func loadRelations(ctx context.Context, db sqlx.QueryerContext, subscriptions []*entity.Subscription) error {
+	if err := loadTags(ctx, db, subscriptions); err != nil {
+		return err
+	}
+	if err := loadPrices(ctx, db, subscriptions); err != nil {
+		return err
+	}
+	if err := loadDiscounts(ctx, db, subscriptions); err != nil {
+		return err
+	}
+	if err := loadParticipants(ctx, db, subscriptions); err != nil {
+		return err
+	}
+	if err := loadPauses(ctx, db, subscriptions); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, s := range subscriptions {
+		s.Evaluate(now)
+	}
+	return nil
+}
+
+// relationBatchSize — сколько подписок загружает один запрос связей. В IN по параметру на подписку,
+// а число параметров ограничено: 65535 в Postgres и 32766 в SQLite.
+const relationBatchSize = 1000
+
+// queryByIDs выполняет query для ID подписок пачками по relationBatchSize, подставляя вместо %s
+// список плейсхолдеров, и передает каждую строку в scan. Связи одной подписки всегда приходят
+// одним запросом, поэтому ORDER BY внутри пачки сохраняет их порядок.
+func queryByIDs(ctx context.Context, db sqlx.QueryerContext, query string, subscriptions []*entity.Subscription, scan func(rows *sql.Rows) error) error {
+	for first := 0; first < len(subscriptions); first += relationBatchSize {
+		batch := subscriptions[first:min(first+relationBatchSize, len(subscriptions))]
+		placeholders := make([]string, len(batch))
+		params := make([]interface{}, len(batch))
+		for i, s := range batch {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+			params[i] = s.ID
+		}
+
+		if err := scanRows(ctx, db, fmt.Sprintf(query, strings.Join(placeholders, ", ")), params, scan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanRows(ctx context.Context, db sqlx.QueryerContext, query string, params []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// indexByID обнуляет связь reset у каждой подписки и возвращает подписки по ID
+func indexByID(subscriptions []*entity.Subscription, reset func(s *entity.Subscription)) map[uuid.UUID]*entity.Subscription {
+	byID := make(map[uuid.UUID]*entity.Subscription, len(subscriptions))
+	for _, s := range subscriptions {
+		reset(s)
+		byID[s.ID] = s
+	}
+	return byID
+}
+
+// loadTags заполняет Tags у subscriptions
+func loadTags(ctx context.Context, db sqlx.QueryerContext, subscriptions []*entity.Subscription) error {
+	byID := indexByID(subscriptions, func(s *entity.Subscription) { s.Tags = []string{} })
+
+	query := `SELECT subscription_id, tag FROM subscription_tags WHERE subscription_id IN (%s) ORDER BY tag`
+	return queryByIDs(ctx, db, query, subscriptions, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return fmt.Errorf("failed to scan subscription tag: %w", err)
+		}
+		if s, ok := byID[id]; ok {
+			s.Tags = append(s.Tags, tag)
+		}
+		return nil
+	})
+}
+
+// loadPrices заполняет Prices у subscriptions
+func loadPrices(ctx context.Context, db sqlx.QueryerContext, subscriptions []*entity.Subscription) error {
+	byID := indexByID(subscriptions, func(s *entity.Subscription) { s.Prices = []entity.PricePeriod{} })
+
+	query := `
+        SELECT subscription_id, effective_from, price FROM subscription_prices
+        WHERE subscription_id IN (%s) ORDER BY effective_from`
+	return queryByIDs(ctx, db, query, subscriptions, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var p entity.PricePeriod
+		if err := rows.Scan(&id, &p.EffectiveFrom, &p.Price); err != nil {
+			return fmt.Errorf("failed to scan subscription price: %w", err)
+		}
+		if s, ok := byID[id]; ok {
+			s.Prices = append(s.Prices, p)
+		}
+		return nil
+	})
+}
+
+// loadDiscounts заполняет Discounts у subscriptions
+func loadDiscounts(ctx context.Context, db sqlx.QueryerContext, subscriptions []*entity.Subscription) error {
+	byID := indexByID(subscriptions, func(s *entity.Subscription) { s.Discounts = []entity.Discount{} })
+
+	query := `
+        SELECT subscription_id, id, name, percent, amount, valid_from, valid_to FROM subscription_discounts
+        WHERE subscription_id IN (%s) ORDER BY valid_from, id`
+	return queryByIDs(ctx, db, query, subscriptions, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var d entity.Discount
+		if err := rows.Scan(&id, &d.ID, &d.Name, &d.Percent, &d.Amount, &d.ValidFrom, &d.ValidTo); err != nil {
+			return fmt.Errorf("failed to scan subscription discount: %w", err)
+		}
+		if s, ok := byID[id]; ok {
+			s.Discounts = append(s.Discounts, d)
+		}
+		return nil
+	})
+}
+
+// loadPauses заполняет Pauses у subscriptions
+func loadPauses(ctx context.Context, db sqlx.QueryerContext, subscriptions []*entity.Subscription) error {
+	byID := indexByID(subscriptions, func(s *entity.Subscription) { s.Pauses = []entity.Pause{} })
+
+	query := `
+        SELECT subscription_id, id, start_date, end_date FROM subscription_pauses
+        WHERE subscription_id IN (%s) ORDER BY start_date, id`
+	return queryByIDs(ctx, db, query, subscriptions, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var p entity.Pause
+		if err := rows.Scan(&id, &p.ID, &p.StartDate, &p.EndDate); err != nil {
+			return fmt.Errorf("failed to scan subscription pause: %w", err)
+		}
+		if s, ok := byID[id]; ok {
+			s.Pauses = append(s.Pauses, p)
+		}
+		return nil
+	})
+}
+
+// loadParticipants заполняет Participants у subscriptions
+func loadParticipants(ctx context.Context, db sqlx.QueryerContext, subscriptions []*entity.Subscription) error {
+	byID := indexByID(subscriptions, func(s *entity.Subscription) { s.Participants = []entity.Participant{} })
+
+	query := `
+        SELECT subscription_id, user_id, weight, amount FROM subscription_participants
+        WHERE subscription_id IN (%s) ORDER BY user_id`
+	return queryByIDs(ctx, db, query, subscriptions, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var p entity.Participant
+		if err := rows.Scan(&id, &p.UserID, &p.Weight, &p.Amount); err != nil {
+			return fmt.Errorf("failed to scan subscription participant: %w", err)
+		}
+		if s, ok := byID[id]; ok {
+			s.Participants = append(s.Participants, p)
+		}
+		return nil
+	})
+}
+
+// selectSubscriptions читает подписки, выбранные в порядке subscriptionColumns, вместе со связями
+func selectSubscriptions(ctx context.Context, db sqlx.QueryerContext, query string, params ...any) ([]*entity.Subscription, error) {
+	rows, err := db.QueryContext(ctx, query, params...)
 	if err != nil {
-		logrus.WithError(err).Error("failed to get subscription summary")
-		return nil, fmt.Errorf("failed to get subscription summary: %w", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*entity.Subscription
+	for rows.Next() {
+		var subscription entity.Subscription
+		if err := scanSubscription(rows, &subscription); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return &summary, nil
+	return subscriptions, loadRelations(ctx, db, subscriptions)
+}
+
+// scoredRow читает перед колонками подписки оценку поиска
+type scoredRow struct {
+	row   interface{ Scan(dest ...any) error }
+	score *float64
+}
+
+func (r scoredRow) Scan(dest ...any) error {
+	return r.row.Scan(append([]any{r.score}, dest...)...)
+}
+
+// escapeLike экранирует служебные символы LIKE, чтобы запрос искался буквально
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// scanSubscription читает строку, выбранную в порядке subscriptionColumns
+func scanSubscription(row interface{ Scan(dest ...any) error }, subscription *entity.Subscription) error {
+	return row.Scan(
+		&subscription.ID,
+		&subscription.ServiceID,
+		&subscription.ServiceName,
+		&subscription.UserID,
+		&subscription.StartDate,
+		&subscription.EndDate,
+		&subscription.TrialEndDate,
+		&subscription.BillingDay,
+		&subscription.CancelReason,
+		&subscription.CancelComment,
+		&subscription.CancelledAt,
+	)
 }
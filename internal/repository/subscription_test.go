@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestExclusionError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		overlap bool
+	}{
+		{name: "exclusion_violation", err: &pq.Error{Code: "23P01"}, overlap: true},
+		{name: "обернутое нарушение", err: fmt.Errorf("commit: %w", &pq.Error{Code: "23P01"}), overlap: true},
+		{name: "unique_violation", err: &pq.Error{Code: "23505"}},
+		{name: "прочая ошибка", err: errors.New("connection refused")},
+		{name: "без ошибки", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exclusionError(tt.err)
+			if (got != nil) != tt.overlap {
+				t.Fatalf("exclusionError(%v) = %v, want overlap %v", tt.err, got, tt.overlap)
+			}
+			// Хендлер отвечает 409 по префиксу, как на отказ проверки в сервисе
+			if got != nil && !strings.HasPrefix(got.Error(), "subscription overlaps") {
+				t.Errorf("exclusionError(%v) = %q, want subscription overlaps prefix", tt.err, got)
+			}
+		})
+	}
+}
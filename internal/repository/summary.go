@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// calendar — выражения для месяцев и дней, которые в Postgres и SQLite пишутся по-разному
+type calendar struct {
+	// months — CTE months(month) с первыми числами месяцев от from до to включительно
+	months func(from, to string) string
+	// monthStart и monthEnd — первый и последний день месяца даты expr
+	monthStart, monthEnd func(expr string) string
+	// days — число дней от first до last включительно
+	days func(first, last string) string
+	// greatest и least — наибольшее и наименьшее из значений
+	greatest, least string
+}
+
+var postgresCalendar = calendar{
+	months: func(from, to string) string {
+		return fmt.Sprintf("months(month) AS (SELECT generate_series(%s::date, %s::date, interval '1 month')::date)", from, to)
+	},
+	monthStart: func(expr string) string { return fmt.Sprintf("date_trunc('month', %s::timestamp)::date", expr) },
+	monthEnd: func(expr string) string {
+		return fmt.Sprintf("(date_trunc('month', %s::timestamp) + interval '1 month - 1 day')::date", expr)
+	},
+	days:     func(first, last string) string { return fmt.Sprintf("(%s - %s + 1)", last, first) },
+	greatest: "GREATEST",
+	least:    "LEAST",
+}
+
+var sqliteCalendar = calendar{
+	months: func(from, to string) string {
+		return fmt.Sprintf(`months(month) AS (
+            SELECT date(%s)
+            UNION ALL
+            SELECT date(month, '+1 month') FROM months WHERE month < date(%s))`, from, to)
+	},
+	monthStart: func(expr string) string { return fmt.Sprintf("date(%s, 'start of month')", expr) },
+	monthEnd:   func(expr string) string { return fmt.Sprintf("date(%s, 'start of month', '+1 month', '-1 day')", expr) },
+	days: func(first, last string) string {
+		return fmt.Sprintf("(CAST(julianday(%s) - julianday(%s) AS INTEGER) + 1)", last, first)
+	},
+	greatest: "max",
+	least:    "min",
+}
+
+// charges строит CTE для запросов summary: filtered — подписки под фильтром req, charges(id, month, price) —
+// их оплачиваемые месяцы окна со стоимостью, как entity.SummaryWindow.Charges. Цена месяца берется
+// из subscription_prices по effective_from, затем применяются процентные скидки по порядку valid_from
+// с округлением вниз и фиксированные скидки; пробные месяцы и месяцы целиком на паузе не оплачиваются.
+func (r *subscriptionRepo) charges(req *entity.SubscriptionSummaryRequest, window entity.SummaryWindow) (string, []interface{}, error) {
+	where, params, err := r.summaryFilter(req)
+	if err != nil {
+		return "", nil, err
+	}
+	params = append(params, window.From, window.To)
+
+	c, date := r.calendar, r.date
+	activeDiscount := func(alias, month string) string {
+		return fmt.Sprintf("%s <= %s AND (%s.valid_to IS NULL OR %s >= %s)",
+			date(alias+".valid_from"), month, alias, date(alias+".valid_to"), month)
+	}
+	price := "p.price"
+	if window.Prorate {
+		total := c.days("p.month", c.monthEnd("p.month"))
+		price = fmt.Sprintf("(p.price * p.days + %[1]s / 2) / %[1]s", total)
+	}
+
+	query := `WITH RECURSIVE
+        filtered AS (
+            SELECT id, user_id, service_name, start_date, end_date, trial_end_date
+            FROM subscriptions WHERE 1=1` + where + `
+        ),
+        ` + c.months(fmt.Sprintf("$%d", len(params)-1), fmt.Sprintf("$%d", len(params))) + `,
+        spans AS (
+            SELECT f.id, m.month,
+                ` + c.greatest + `(m.month, ` + date("f.start_date") + `) AS first_day,
+                ` + c.least + `(` + c.monthEnd("m.month") + `, COALESCE(` + date("f.end_date") + `, ` + c.monthEnd("m.month") + `)) AS last_day
+            FROM filtered f
+            JOIN months m ON m.month >= ` + c.monthStart("f.start_date") + `
+                AND (f.end_date IS NULL OR m.month <= ` + date("f.end_date") + `)
+            WHERE f.trial_end_date IS NULL OR m.month > ` + date("f.trial_end_date") + `
+        ),
+        active AS (
+            SELECT s.id, s.month, ` + c.days("s.first_day", "s.last_day") + ` - COALESCE((
+                SELECT SUM(` + c.days(
+		c.greatest+"("+date("p.start_date")+", s.first_day)",
+		c.least+"(COALESCE("+date("p.end_date")+", s.last_day), s.last_day)") + `)
+                FROM subscription_pauses p
+                WHERE p.subscription_id = s.id AND ` + date("p.start_date") + ` <= s.last_day
+                    AND (p.end_date IS NULL OR ` + date("p.end_date") + ` >= s.first_day)
+            ), 0) AS days
+            FROM spans s
+        ),
+        percents AS (
+            SELECT a.id, a.month, d.percent,
+                ROW_NUMBER() OVER (PARTITION BY a.id, a.month ORDER BY ` + date("d.valid_from") + `, d.id) AS n
+            FROM active a
+            JOIN subscription_discounts d ON d.subscription_id = a.id
+            WHERE a.days > 0 AND d.percent IS NOT NULL AND ` + activeDiscount("d", "a.month") + `
+        ),
+        discounted(id, month, days, n, price) AS (
+            SELECT a.id, a.month, a.days, 0, COALESCE(
+                (SELECT p.price FROM subscription_prices p
+                 WHERE p.subscription_id = a.id AND ` + date("p.effective_from") + ` <= a.month
+                 ORDER BY p.effective_from DESC LIMIT 1),
+                (SELECT p.price FROM subscription_prices p
+                 WHERE p.subscription_id = a.id
+                 ORDER BY p.effective_from LIMIT 1))
+            FROM active a
+            WHERE a.days > 0
+            UNION ALL
+            SELECT d.id, d.month, d.days, d.n + 1, d.price - d.price * p.percent / 100
+            FROM discounted d
+            JOIN percents p ON p.id = d.id AND p.month = d.month AND p.n = d.n + 1
+        ),
+        priced AS (
+            SELECT d.id, d.month, d.days, ` + c.greatest + `(d.price - COALESCE((
+                SELECT SUM(x.amount) FROM subscription_discounts x
+                WHERE x.subscription_id = d.id AND x.amount IS NOT NULL AND ` + activeDiscount("x", "d.month") + `
+            ), 0), 0) AS price
+            FROM discounted d
+            WHERE d.n = (SELECT COUNT(*) FROM percents p WHERE p.id = d.id AND p.month = d.month)
+        ),
+        charges AS (
+            SELECT p.id, p.month, ` + price + ` AS price FROM priced p
+        )`
+
+	return query, params, nil
+}
+
+// Summary считает стоимость подписок под фильтром summary по месяцам окна целиком в базе
+func (r *subscriptionRepo) Summary(ctx context.Context, req *entity.SubscriptionSummaryRequest) (*entity.SubscriptionSummary, error) {
+	window, err := req.Window(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query, params, err := r.charges(req, window)
+	if err != nil {
+		return nil, err
+	}
+	query += `
+        SELECT (SELECT COALESCE(SUM(price), 0) FROM charges), (SELECT COUNT(*) FROM filtered)`
+
+	summary := &entity.SubscriptionSummary{}
+	err = observe(ctx, "SubscriptionRepository.Summary", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).QueryRowContext(ctx, query, params...).Scan(&summary.TotalCost, &summary.Count)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to calculate summary")
+		return nil, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// SpendByTag разбивает Summary по тегам: подписка с несколькими тегами входит в каждый,
+// подписки без тегов — под пустым тегом
+func (r *subscriptionRepo) SpendByTag(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.TagSpend, error) {
+	window, err := req.Window(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query, params, err := r.charges(req, window)
+	if err != nil {
+		return nil, err
+	}
+	query += `,
+        costs AS (
+            SELECT id, SUM(price) AS cost FROM charges GROUP BY id
+        )
+        SELECT COALESCE(t.tag, '') AS tag, COALESCE(SUM(c.cost), 0) AS total_cost, COUNT(*) AS count
+        FROM filtered f
+        LEFT JOIN subscription_tags t ON t.subscription_id = f.id
+        LEFT JOIN costs c ON c.id = f.id
+        GROUP BY COALESCE(t.tag, '')`
+
+	spend := []*entity.TagSpend{}
+	err = observe(ctx, "SubscriptionRepository.SpendByTag", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).SelectContext(ctx, &spend, query, params...)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to calculate spend by tag")
+		return nil, fmt.Errorf("failed to calculate spend by tag: %w", err)
+	}
+	// Порядок строк в Postgres зависит от collation, а теги сравниваются побайтово, как в Go
+	sort.Slice(spend, func(i, j int) bool { return spend[i].Tag < spend[j].Tag })
+
+	return spend, nil
+}
+
+// MonthlySpend раскладывает стоимость подписок под фильтром summary по месяцам окна, сервисам
+// и плательщикам; подписки из cancel считаются отдельно
+func (r *subscriptionRepo) MonthlySpend(ctx context.Context, req *entity.SubscriptionSummaryRequest, cancel []uuid.UUID) ([]*entity.MonthSpend, error) {
+	window, err := req.Window(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query, params, err := r.charges(req, window)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled := "1 = 0"
+	if len(cancel) > 0 {
+		placeholders := make([]string, len(cancel))
+		for i, id := range cancel {
+			params = append(params, id)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		cancelled = "f.id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	query += `
+        SELECT c.month, f.service_name, f.user_id, ` + cancelled + `, SUM(c.price)
+        FROM charges c
+        JOIN filtered f ON f.id = c.id
+        GROUP BY c.month, f.service_name, f.user_id, ` + cancelled
+
+	spend := []*entity.MonthSpend{}
+	err = observe(ctx, "SubscriptionRepository.MonthlySpend", query, func(ctx context.Context) error {
+		spend = spend[:0]
+		return scanRows(ctx, reader(ctx, r.db, r.replica), query, params, func(rows *sql.Rows) error {
+			var s entity.MonthSpend
+			if err := rows.Scan((*monthValue)(&s.Month), &s.ServiceName, &s.UserID, &s.Cancelled, &s.Cost); err != nil {
+				return err
+			}
+			spend = append(spend, &s)
+			return nil
+		})
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to calculate monthly spend")
+		return nil, fmt.Errorf("failed to calculate monthly spend: %w", err)
+	}
+	sortMonthSpend(spend)
+
+	return spend, nil
+}
+
+// ActiveStats считает число подписок, действующих в месяце month, и их стоимость за этот месяц
+// по сервисам. Вызывается на каждый сбор метрик, поэтому подписки в Go не читаются.
+func (r *subscriptionRepo) ActiveStats(ctx context.Context, month time.Time) ([]*entity.ServiceStats, error) {
+	period := month.Format(entity.MonthLayout)
+	req := &entity.SubscriptionSummaryRequest{StartPeriod: &period, EndPeriod: &period}
+	query, params, err := r.charges(req, entity.SummaryWindow{From: month, To: month})
+	if err != nil {
+		return nil, err
+	}
+	query += `,
+        costs AS (
+            SELECT id, SUM(price) AS cost FROM charges GROUP BY id
+        )
+        SELECT f.service_name, COUNT(*) AS active_count, COALESCE(SUM(c.cost), 0) AS monthly_spend
+        FROM filtered f
+        LEFT JOIN costs c ON c.id = f.id
+        GROUP BY f.service_name`
+
+	stats := []*entity.ServiceStats{}
+	err = observe(ctx, "SubscriptionRepository.ActiveStats", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).SelectContext(ctx, &stats, query, params...)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to calculate active stats")
+		return nil, fmt.Errorf("failed to calculate active stats: %w", err)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ServiceName < stats[j].ServiceName })
+
+	return stats, nil
+}
+
+// sortMonthSpend упорядочивает строки MonthlySpend одинаково для всех хранилищ
+func sortMonthSpend(spend []*entity.MonthSpend) {
+	sort.Slice(spend, func(i, j int) bool {
+		a, b := spend[i], spend[j]
+		switch {
+		case !a.Month.Equal(b.Month):
+			return a.Month.Before(b.Month)
+		case a.ServiceName != b.ServiceName:
+			return a.ServiceName < b.ServiceName
+		case a.UserID != b.UserID:
+			return a.UserID.String() < b.UserID.String()
+		default:
+			return !a.Cancelled && b.Cancelled
+		}
+	})
+}
+
+// monthValue читает месяц из CTE months: Postgres возвращает DATE, а SQLite — строку YYYY-MM-DD
+type monthValue time.Time
+
+func (m *monthValue) Scan(value any) error {
+	var month time.Time
+	switch v := value.(type) {
+	case time.Time:
+		month = v
+	case string, []byte:
+		text := fmt.Sprintf("%s", v)
+		parsed, err := time.Parse(entity.DateLayout, text[:min(len(text), len(entity.DateLayout))])
+		if err != nil {
+			return fmt.Errorf("invalid month %q: %w", text, err)
+		}
+		month = parsed
+	default:
+		return fmt.Errorf("unsupported month type %T", value)
+	}
+	*m = monthValue(entity.MonthStart(month))
+	return nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *entity.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
+	Update(ctx context.Context, user *entity.User) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, limit, offset int) ([]*entity.User, error)
+}
+
+type userRepo struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+// NewUserRepository создает репозиторий пользователей; запросы переносимы между Postgres и SQLite
+func NewUserRepository(db, replica *sqlx.DB) UserRepository {
+	return &userRepo{db: db, replica: replica}
+}
+
+const userColumns = `id, display_name, email, currency, created_at`
+
+func (r *userRepo) Create(ctx context.Context, user *entity.User) error {
+	query := `
+        INSERT INTO users (id, display_name, email, currency, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+
+	err := observeWrite(ctx, "UserRepository.Create", query, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, query,
+			user.ID,
+			user.DisplayName,
+			user.Email,
+			user.Currency,
+			user.CreatedAt,
+		)
+		return err
+	})
+
+	if isUniqueViolation(err) {
+		return fmt.Errorf("user already exists")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to create user")
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	logger.FromContext(ctx).Infof("User created successfully: %s", user.ID)
+	return nil
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	var user entity.User
+	err := observe(ctx, "UserRepository.GetByID", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).GetContext(ctx, &user, query, id)
+	})
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to get user by ID")
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// Update сохраняет профиль пользователя целиком
+func (r *userRepo) Update(ctx context.Context, user *entity.User) error {
+	query := `
+        UPDATE users SET display_name = $1, email = $2, currency = $3
+        WHERE id = $4
+    `
+
+	var rowsAffected int64
+	err := observeWrite(ctx, "UserRepository.Update", query, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query,
+			user.DisplayName,
+			user.Email,
+			user.Currency,
+			user.ID,
+		)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		return nil
+	})
+
+	if isUniqueViolation(err) {
+		return fmt.Errorf("user already exists")
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to update user")
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.FromContext(ctx).Infof("User updated successfully: %s", user.ID)
+	return nil
+}
+
+// Delete удаляет пользователя, который не оплачивает подписки и не участвует в них
+func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := "DELETE FROM users WHERE id = $1"
+
+	var hasSubscriptions bool
+	var rowsAffected int64
+	err := observeWrite(ctx, "UserRepository.Delete", query, func(ctx context.Context) error {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		err = tx.GetContext(ctx, &hasSubscriptions, `
+            SELECT EXISTS (SELECT 1 FROM subscriptions WHERE user_id = $1)
+                OR EXISTS (SELECT 1 FROM subscription_participants WHERE user_id = $1)`, id)
+		if err != nil || hasSubscriptions {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+
+		return tx.Commit()
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to delete user")
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if hasSubscriptions {
+		return fmt.Errorf("user has subscriptions")
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.FromContext(ctx).Infof("User deleted successfully: %s", id)
+	return nil
+}
+
+func (r *userRepo) List(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+	query := `
+        SELECT ` + userColumns + `
+        FROM users
+        ORDER BY created_at, id
+        LIMIT $1 OFFSET $2
+    `
+
+	var users []*entity.User
+	err := observe(ctx, "UserRepository.List", query, func(ctx context.Context) error {
+		return reader(ctx, r.db, r.replica).SelectContext(ctx, &users, query, limit, offset)
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to list users")
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+)
+
+// billingWindow — месяцы, за которые считается стоимость в summary. В Go стоимость считается только там,
+// где нужны доли участников; без них summary считает хранилище (см. SubscriptionRepository.Summary).
+type billingWindow struct {
+	entity.SummaryWindow
+}
+
+func newBillingWindow(req *entity.SubscriptionSummaryRequest) billingWindow {
+	// Форматы уже проверены в prepareSummary
+	window, _ := req.Window(time.Now())
+	return billingWindow{window}
+}
+
+// cost возвращает стоимость подписки за окно; с userID — только долю пользователя
+func (w billingWindow) cost(s *entity.Subscription, userID *uuid.UUID) int {
+	total := 0
+	w.Charges(s, func(month time.Time, price int) {
+		if userID == nil {
+			total += price
+			return
+		}
+		share, _ := s.ShareOf(*userID, price)
+		total += share
+	})
+	return total
+}
+
+func (w billingWindow) summary(subscriptions []*entity.Subscription, userID *uuid.UUID) *entity.SubscriptionSummary {
+	summary := &entity.SubscriptionSummary{}
+	for _, s := range subscriptions {
+		summary.TotalCost += w.cost(s, userID)
+		summary.Count++
+	}
+	return summary
+}
+
+// spendByTag разбивает summary по тегам: подписка с несколькими тегами входит в каждый,
+// подписки без тегов — под пустым тегом
+func (w billingWindow) spendByTag(subscriptions []*entity.Subscription, userID *uuid.UUID) []*entity.TagSpend {
+	byTag := make(map[string]*entity.TagSpend)
+	for _, s := range subscriptions {
+		cost := w.cost(s, userID)
+		tags := s.Tags
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		for _, tag := range tags {
+			spend, ok := byTag[tag]
+			if !ok {
+				spend = &entity.TagSpend{Tag: tag}
+				byTag[tag] = spend
+			}
+			spend.TotalCost += cost
+			spend.Count++
+		}
+	}
+
+	spend := make([]*entity.TagSpend, 0, len(byTag))
+	for _, s := range byTag {
+		spend = append(spend, s)
+	}
+	sort.Slice(spend, func(i, j int) bool { return spend[i].Tag < spend[j].Tag })
+
+	return spend
+}
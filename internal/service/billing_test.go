@@ -0,0 +1,160 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+)
+
+func day(value string) time.Time {
+	t, err := entity.ParseDate(value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// charged возвращает оплаченные месяцы окна в виде MM-YYYY → цена
+func charged(w billingWindow, s *entity.Subscription) map[string]int {
+	months := make(map[string]int)
+	w.Charges(s, func(month time.Time, price int) {
+		months[month.Format(entity.MonthLayout)] = price
+	})
+	return months
+}
+
+func window(from, to string, prorate bool) billingWindow {
+	return newBillingWindow(&entity.SubscriptionSummaryRequest{StartPeriod: &from, EndPeriod: &to, Prorate: prorate})
+}
+
+func equalCharges(got, want map[string]int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for month, price := range want {
+		if p, ok := got[month]; !ok || p != price {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBillingWindowPauses(t *testing.T) {
+	tests := []struct {
+		name   string
+		pauses []entity.Pause
+		want   map[string]int
+	}{
+		{
+			name: "без пауз",
+			want: map[string]int{"01-2026": 100, "02-2026": 100, "03-2026": 100, "04-2026": 100},
+		},
+		{
+			name:   "весь месяц на паузе",
+			pauses: []entity.Pause{{StartDate: day("2026-02-01"), EndDate: ptr(day("2026-02-28"))}},
+			want:   map[string]int{"01-2026": 100, "03-2026": 100, "04-2026": 100},
+		},
+		{
+			name:   "часть месяца на паузе",
+			pauses: []entity.Pause{{StartDate: day("2026-03-10"), EndDate: ptr(day("2026-03-19"))}},
+			want:   map[string]int{"01-2026": 100, "02-2026": 100, "03-2026": 100, "04-2026": 100},
+		},
+		{
+			name:   "открытая пауза",
+			pauses: []entity.Pause{{StartDate: day("2026-03-01")}},
+			want:   map[string]int{"01-2026": 100, "02-2026": 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &entity.Subscription{Price: 100, StartDate: day("2025-06-01"), Pauses: tt.pauses}
+			if got := charged(window("01-2026", "04-2026", false), s); !equalCharges(got, tt.want) {
+				t.Errorf("charges = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Без периода оплачивается только текущий месяц, и так же без пробных месяцев и месяцев на паузе
+func TestBillingWindowCurrentMonth(t *testing.T) {
+	month := entity.MonthStart(time.Now())
+	previous := month.AddDate(0, -1, 0)
+
+	tests := []struct {
+		name string
+		sub  entity.Subscription
+		want bool
+	}{
+		{name: "действует", sub: entity.Subscription{StartDate: previous}, want: true},
+		{name: "пробный месяц", sub: entity.Subscription{StartDate: previous, TrialEndDate: &month}},
+		{name: "на паузе весь месяц", sub: entity.Subscription{StartDate: previous, Pauses: []entity.Pause{{StartDate: previous}}}},
+		{
+			name: "пауза закончилась в этом месяце",
+			sub:  entity.Subscription{StartDate: previous, Pauses: []entity.Pause{{StartDate: previous, EndDate: &month}}},
+			want: true,
+		},
+		{name: "закончилась в прошлом месяце", sub: entity.Subscription{StartDate: previous, EndDate: ptr(month.AddDate(0, 0, -1))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.sub.Price = 100
+			tt.sub.Evaluate(time.Now())
+			got := charged(newBillingWindow(&entity.SubscriptionSummaryRequest{}), &tt.sub)
+			want := map[string]int{}
+			if tt.want {
+				want[month.Format(entity.MonthLayout)] = 100
+			}
+			if !equalCharges(got, want) {
+				t.Errorf("charges = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBillingWindowProrate(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  entity.Subscription
+		want map[string]int
+	}{
+		{
+			name: "начало и конец посреди месяца",
+			sub:  entity.Subscription{StartDate: day("2026-01-17"), EndDate: ptr(day("2026-03-10"))},
+			// 15 из 31 дня января, весь февраль, 10 из 31 дня марта
+			want: map[string]int{"01-2026": 150, "02-2026": 310, "03-2026": 100},
+		},
+		{
+			name: "пауза внутри месяца",
+			sub: entity.Subscription{
+				StartDate: day("2025-12-01"),
+				Pauses:    []entity.Pause{{StartDate: day("2026-02-01"), EndDate: ptr(day("2026-02-14"))}},
+			},
+			want: map[string]int{"01-2026": 310, "02-2026": 155, "03-2026": 310},
+		},
+		{
+			name: "пробный месяц не оплачивается и не дробится",
+			sub:  entity.Subscription{StartDate: day("2026-01-17"), TrialEndDate: ptr(day("2026-01-01"))},
+			want: map[string]int{"02-2026": 310, "03-2026": 310},
+		},
+		{
+			name: "округление до ближайшего",
+			sub:  entity.Subscription{StartDate: day("2026-03-31"), Prices: []entity.PricePeriod{{EffectiveFrom: day("2026-03-01"), Price: 15}}},
+			// 15 * 1 / 31 = 0.48 → 0
+			want: map[string]int{"03-2026": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sub.Prices == nil {
+				tt.sub.Price = 310
+			}
+			if got := charged(window("01-2026", "03-2026", true), &tt.sub); !equalCharges(got, tt.want) {
+				t.Errorf("charges = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
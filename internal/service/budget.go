@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type BudgetService interface {
+	CreateBudget(ctx context.Context, userID uuid.UUID, req *entity.CreateBudgetRequest) (*entity.Budget, error)
+	UpdateBudget(ctx context.Context, userID, id uuid.UUID, req *entity.UpdateBudgetRequest) (*entity.Budget, error)
+	DeleteBudget(ctx context.Context, userID, id uuid.UUID) error
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]*entity.Budget, error)
+	GetBudgetStatus(ctx context.Context, userID uuid.UUID) ([]*entity.BudgetStatus, error)
+}
+
+type budgetService struct {
+	repo          repository.BudgetRepository
+	subscriptions repository.SubscriptionRepository
+	catalog       repository.CatalogRepository
+	users         repository.UserRepository
+}
+
+func NewBudgetService(repo repository.BudgetRepository, subscriptions repository.SubscriptionRepository, catalog repository.CatalogRepository, users repository.UserRepository) BudgetService {
+	return &budgetService{repo: repo, subscriptions: subscriptions, catalog: catalog, users: users}
+}
+
+func (s *budgetService) CreateBudget(ctx context.Context, userID uuid.UUID, req *entity.CreateBudgetRequest) (*entity.Budget, error) {
+	ctx, span := tracer.Start(ctx, "BudgetService.CreateBudget")
+	defer span.End()
+
+	if _, err := s.users.GetByID(repository.WithPrimary(ctx), userID); err != nil {
+		return nil, err
+	}
+
+	budget := &entity.Budget{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Period:    req.Period,
+		Amount:    req.Amount,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	// Бюджет на неизвестный сервис не имеет смысла, поэтому в каталог он не добавляется
+	switch {
+	case req.ServiceID != nil:
+		service, err := s.catalog.GetByID(repository.WithPrimary(ctx), *req.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		budget.ServiceID = &service.ID
+	case req.ServiceName != nil:
+		service, err := s.catalog.FindByName(ctx, cleanServiceName(*req.ServiceName))
+		if err != nil {
+			return nil, err
+		}
+		budget.ServiceID = &service.ID
+	case req.Tag != nil:
+		tags, err := normalizeTags([]string{*req.Tag})
+		if err != nil {
+			return nil, err
+		}
+		budget.Tag = &tags[0]
+	}
+
+	if err := s.repo.Create(ctx, budget); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (s *budgetService) UpdateBudget(ctx context.Context, userID, id uuid.UUID, req *entity.UpdateBudgetRequest) (*entity.Budget, error) {
+	ctx, span := tracer.Start(ctx, "BudgetService.UpdateBudget")
+	defer span.End()
+
+	budget, err := s.userBudget(repository.WithPrimary(ctx), userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Period != nil {
+		budget.Period = *req.Period
+	}
+	if req.Amount != nil {
+		budget.Amount = *req.Amount
+	}
+
+	if err := s.repo.Update(ctx, budget); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (s *budgetService) DeleteBudget(ctx context.Context, userID, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "BudgetService.DeleteBudget")
+	defer span.End()
+
+	if _, err := s.userBudget(repository.WithPrimary(ctx), userID, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *budgetService) ListBudgets(ctx context.Context, userID uuid.UUID) ([]*entity.Budget, error) {
+	ctx, span := tracer.Start(ctx, "BudgetService.ListBudgets")
+	defer span.End()
+
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// GetBudgetStatus сравнивает каждый бюджет пользователя с расходами в текущем периоде
+func (s *budgetService) GetBudgetStatus(ctx context.Context, userID uuid.UUID) ([]*entity.BudgetStatus, error) {
+	ctx, span := tracer.Start(ctx, "BudgetService.GetBudgetStatus")
+	defer span.End()
+
+	budgets, err := s.ListBudgets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := make([]*entity.BudgetStatus, 0, len(budgets))
+	for _, b := range budgets {
+		status, err := budgetStatus(ctx, s.subscriptions, b, now)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// userBudget возвращает бюджет, только если он принадлежит пользователю
+func (s *budgetService) userBudget(ctx context.Context, userID, id uuid.UUID) (*entity.Budget, error) {
+	budget, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if budget.UserID != userID {
+		return nil, fmt.Errorf("budget not found")
+	}
+	return budget, nil
+}
+
+// budgetStatus считает расходы пользователя под бюджетом так же, как summary с user_id:
+// за текущий месяц или календарный год, по цене после скидок, без пробных месяцев
+func budgetStatus(ctx context.Context, subscriptions repository.SubscriptionRepository, b *entity.Budget, now time.Time) (*entity.BudgetStatus, error) {
+	month := entity.MonthStart(now)
+	from, to := month, month
+	if b.Period == entity.BudgetYearly {
+		from = time.Date(month.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 11, 0)
+	}
+
+	startPeriod, endPeriod := from.Format("01-2006"), to.Format("01-2006")
+	req := &entity.SubscriptionSummaryRequest{UserID: &b.UserID, StartPeriod: &startPeriod, EndPeriod: &endPeriod}
+	if b.Tag != nil {
+		req.Tags = []string{*b.Tag}
+	}
+	found, err := subscriptions.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if b.ServiceID != nil {
+		found = slices.DeleteFunc(found, func(s *entity.Subscription) bool { return s.ServiceID != *b.ServiceID })
+	}
+
+	projected := billingWindow{entity.SummaryWindow{From: from, To: to}}.summary(found, &b.UserID).TotalCost
+	actual := billingWindow{entity.SummaryWindow{From: from, To: month}}.summary(found, &b.UserID).TotalCost
+
+	return &entity.BudgetStatus{
+		Budget:      *b,
+		PeriodStart: startPeriod,
+		PeriodEnd:   endPeriod,
+		Actual:      actual,
+		Projected:   projected,
+		Remaining:   b.Amount - projected,
+		Exceeded:    projected > b.Amount,
+	}, nil
+}
+
+// checkBudgets создает событие budget_exceeded для бюджетов плательщика и участников, под которые
+// попадает subscription и прогноз по которым превышен. Событие создается один раз на бюджет,
+// лимит и период; ошибки только логируются — на сохранение подписки они не влияют.
+// Вызывается после каждого изменения, которое может увеличить расходы: создания и обновления подписки,
+// новой цены, тегов, возобновления, удаления скидки и объединения дубликатов.
+func (s *subscriptionService) checkBudgets(ctx context.Context, subscription *entity.Subscription) {
+	ctx = repository.WithPrimary(ctx)
+	log := logger.FromContext(ctx).WithField("subscription_id", subscription.ID)
+
+	userIDs := []uuid.UUID{subscription.UserID}
+	for _, p := range subscription.Participants {
+		if p.UserID != subscription.UserID {
+			userIDs = append(userIDs, p.UserID)
+		}
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		budgets, err := s.budgets.ListByUser(ctx, userID)
+		if err != nil {
+			log.WithError(err).Warn("Failed to check budgets")
+			return
+		}
+
+		for _, b := range budgets {
+			if (b.ServiceID != nil && *b.ServiceID != subscription.ServiceID) ||
+				(b.Tag != nil && !slices.Contains(subscription.Tags, *b.Tag)) {
+				continue
+			}
+
+			status, err := budgetStatus(ctx, s.repo, b, now)
+			if err != nil {
+				log.WithError(err).Warn("Failed to check budgets")
+				return
+			}
+			if !status.Exceeded {
+				continue
+			}
+
+			event := &entity.Event{
+				ID:             uuid.New(),
+				Type:           entity.EventBudgetExceeded,
+				UserID:         userID,
+				SubscriptionID: &subscription.ID,
+				Message: fmt.Sprintf("Projected %s spend %d for %s-%s exceeds the budget of %d",
+					b.Period, status.Projected, status.PeriodStart, status.PeriodEnd, b.Amount),
+				DedupKey:  fmt.Sprintf("%s:%s:%d:%s", entity.EventBudgetExceeded, b.ID, b.Amount, status.PeriodStart),
+				CreatedAt: now.UTC(),
+			}
+			created, err := s.events.Create(ctx, event)
+			if err != nil {
+				log.WithError(err).Warn("Failed to create budget alert")
+				return
+			}
+			if created {
+				log.WithFields(logrus.Fields{"user_id": userID, "budget_id": b.ID}).Info(event.Message)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+)
+
+// TestBudgetAlerts проверяет, что изменения, после которых подписка дороже или попадает под бюджет,
+// создают событие budget_exceeded так же, как создание подписки
+func TestBudgetAlerts(t *testing.T) {
+	month := entity.MonthStart(time.Now())
+
+	tests := []struct {
+		name   string
+		budget entity.CreateBudgetRequest
+		change func(ctx context.Context, svc SubscriptionService, id uuid.UUID) error
+	}{
+		{
+			name:   "новая цена",
+			budget: entity.CreateBudgetRequest{Period: entity.BudgetMonthly, Amount: 150},
+			change: func(ctx context.Context, svc SubscriptionService, id uuid.UUID) error {
+				_, err := svc.SchedulePrice(ctx, id, &entity.SchedulePriceRequest{Price: 200, EffectiveFrom: month.Format(entity.MonthLayout)})
+				return err
+			},
+		},
+		{
+			name:   "новый тег",
+			budget: entity.CreateBudgetRequest{Period: entity.BudgetMonthly, Amount: 50, Tag: ptr("video")},
+			change: func(ctx context.Context, svc SubscriptionService, id uuid.UUID) error {
+				_, err := svc.AddTags(ctx, id, []string{"Video"})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, userID := newTestService(t, config.OverlapWarn)
+			if _, err := svc.BudgetService.CreateBudget(ctx, userID, &tt.budget); err != nil {
+				t.Fatal(err)
+			}
+			sub, err := svc.SubscriptionService.CreateSubscription(ctx, &entity.CreateSubscriptionRequest{
+				ServiceName: "Netflix", Price: 100, UserID: userID, StartDate: month.AddDate(0, -1, 0).Format(entity.DateLayout),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if alerts := budgetAlerts(t, svc, userID); alerts != 0 {
+				t.Fatalf("alerts before change = %d, want 0", alerts)
+			}
+
+			if err := tt.change(ctx, svc.SubscriptionService, sub.ID); err != nil {
+				t.Fatal(err)
+			}
+			if alerts := budgetAlerts(t, svc, userID); alerts != 1 {
+				t.Errorf("alerts after change = %d, want 1", alerts)
+			}
+		})
+	}
+}
+
+func budgetAlerts(t *testing.T, svc *Service, userID uuid.UUID) int {
+	t.Helper()
+	events, err := svc.EventService.ListEvents(context.Background(), userID, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, e := range events {
+		if e.Type == entity.EventBudgetExceeded {
+			count++
+		}
+	}
+	return count
+}
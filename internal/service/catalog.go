@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/google/uuid"
+)
+
+type CatalogService interface {
+	CreateService(ctx context.Context, req *entity.CreateServiceRequest) (*entity.Service, error)
+	GetService(ctx context.Context, id uuid.UUID) (*entity.Service, error)
+	UpdateService(ctx context.Context, id uuid.UUID, req *entity.UpdateServiceRequest) (*entity.Service, error)
+	DeleteService(ctx context.Context, id uuid.UUID) error
+	ListServices(ctx context.Context, limit, offset int) ([]*entity.Service, error)
+}
+
+type catalogService struct {
+	repo repository.CatalogRepository
+}
+
+func NewCatalogService(repo repository.CatalogRepository) CatalogService {
+	return &catalogService{repo: repo}
+}
+
+func (s *catalogService) CreateService(ctx context.Context, req *entity.CreateServiceRequest) (*entity.Service, error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.CreateService")
+	defer span.End()
+
+	service := &entity.Service{
+		ID:           uuid.New(),
+		Name:         cleanServiceName(req.Name),
+		Aliases:      req.Aliases,
+		Category:     strings.TrimSpace(req.Category),
+		Website:      strings.TrimSpace(req.Website),
+		DefaultPrice: req.DefaultPrice,
+	}
+	if err := s.prepare(ctx, service); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, service); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (s *catalogService) GetService(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.GetService")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *catalogService) UpdateService(ctx context.Context, id uuid.UUID, req *entity.UpdateServiceRequest) (*entity.Service, error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.UpdateService")
+	defer span.End()
+
+	service, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		service.Name = cleanServiceName(*req.Name)
+	}
+	if req.Aliases != nil {
+		service.Aliases = *req.Aliases
+	}
+	if req.Category != nil {
+		service.Category = strings.TrimSpace(*req.Category)
+	}
+	if req.Website != nil {
+		service.Website = strings.TrimSpace(*req.Website)
+	}
+	if req.DefaultPrice != nil {
+		service.DefaultPrice = req.DefaultPrice
+		if *req.DefaultPrice == 0 {
+			service.DefaultPrice = nil
+		}
+	}
+
+	if err := s.prepare(ctx, service); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, service); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (s *catalogService) DeleteService(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "CatalogService.DeleteService")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *catalogService) ListServices(ctx context.Context, limit, offset int) ([]*entity.Service, error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.ListServices")
+	defer span.End()
+
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.List(ctx, limit, offset)
+}
+
+// prepare убирает пустые и повторяющиеся алиасы и проверяет, что ни название,
+// ни алиасы не заняты другим сервисом: уникальные индексы этого не видят,
+// если название одного сервиса совпадает с алиасом другого
+func (s *catalogService) prepare(ctx context.Context, service *entity.Service) error {
+	if service.Name == "" {
+		return fmt.Errorf("service name is required")
+	}
+
+	seen := map[string]bool{entity.NormalizeServiceName(service.Name): true}
+	aliases := []string{}
+	for _, alias := range service.Aliases {
+		alias = cleanServiceName(alias)
+		key := entity.NormalizeServiceName(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		aliases = append(aliases, alias)
+	}
+	service.Aliases = aliases
+
+	for key := range seen {
+		existing, err := s.repo.FindByName(ctx, key)
+		if err != nil && err.Error() != "service not found" {
+			return err
+		}
+		if existing != nil && existing.ID != service.ID {
+			return fmt.Errorf("service already exists")
+		}
+	}
+
+	return nil
+}
+
+// resolveService находит сервис подписки по id или по названию/алиасу.
+// Неизвестное название добавляется в каталог, чтобы создание подписок работало как раньше.
+func resolveService(ctx context.Context, repo repository.CatalogRepository, id *uuid.UUID, name string) (*entity.Service, error) {
+	if id != nil {
+		return repo.GetByID(repository.WithPrimary(ctx), *id)
+	}
+
+	name = cleanServiceName(name)
+	if name == "" {
+		return nil, fmt.Errorf("service_name or service_id is required")
+	}
+
+	service, err := repo.FindByName(ctx, name)
+	if err == nil || err.Error() != "service not found" {
+		return service, err
+	}
+
+	service = &entity.Service{ID: uuid.New(), Name: name, Aliases: []string{}}
+	err = repo.Create(ctx, service)
+	if err != nil && err.Error() == "service already exists" {
+		// Сервис успел создать параллельный запрос
+		return repo.FindByName(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// cleanServiceName убирает лишние пробелы, сохраняя регистр
+func cleanServiceName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
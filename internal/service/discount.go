@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/google/uuid"
+)
+
+// AddDiscount добавляет скидку и возвращает подписку со всеми скидками. Как и цены,
+// скидка действует только с текущего месяца: summary за прошлые месяцы уже посчитан.
+func (s *subscriptionService) AddDiscount(ctx context.Context, id uuid.UUID, req *entity.DiscountRequest) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.AddDiscount")
+	defer span.End()
+
+	current, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	from := entity.MonthStart(time.Now())
+	if start := entity.MonthStart(current.StartDate); start.After(from) {
+		from = start
+	}
+	discount, err := parseDiscount(from, req)
+	if err != nil {
+		return nil, err
+	}
+	if discount.ValidFrom.Before(from) {
+		return nil, fmt.Errorf("invalid discount: valid_from must not be before %s", from.Format("01-2006"))
+	}
+
+	if err := s.repo.AddDiscount(ctx, id, discount); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(repository.WithPrimary(ctx), id)
+}
+
+// RemoveDiscount удаляет скидку, которая еще не начала действовать
+func (s *subscriptionService) RemoveDiscount(ctx context.Context, id, discountID uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.RemoveDiscount")
+	defer span.End()
+
+	current, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(current.Discounts, func(d entity.Discount) bool { return d.ID == discountID })
+	if i < 0 {
+		return fmt.Errorf("discount not found")
+	}
+	if current.Discounts[i].ValidFrom.Before(entity.MonthStart(time.Now())) {
+		return fmt.Errorf("invalid discount: discounts that already started can not be removed")
+	}
+
+	if err := s.repo.DeleteDiscount(ctx, id, discountID); err != nil {
+		return err
+	}
+
+	if updated, err := s.repo.GetByID(repository.WithPrimary(ctx), id); err == nil {
+		s.checkBudgets(ctx, updated)
+	}
+	return nil
+}
+
+// parseDiscount проверяет запрос и возвращает скидку; без valid_from она действует с месяца from
+func parseDiscount(from time.Time, req *entity.DiscountRequest) (entity.Discount, error) {
+	if (req.Percent == nil) == (req.Amount == nil) {
+		return entity.Discount{}, fmt.Errorf("invalid discount: exactly one of percent or amount is required")
+	}
+
+	discount := entity.Discount{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(req.Name),
+		Percent:   req.Percent,
+		Amount:    req.Amount,
+		ValidFrom: entity.MonthStart(from),
+	}
+
+	if req.ValidFrom != nil {
+		validFrom, err := time.Parse("01-2006", *req.ValidFrom)
+		if err != nil {
+			return entity.Discount{}, fmt.Errorf("invalid discount valid_from format: %w", err)
+		}
+		discount.ValidFrom = validFrom
+	}
+
+	switch {
+	case req.Months != nil:
+		validTo := discount.ValidFrom.AddDate(0, *req.Months-1, 0)
+		discount.ValidTo = &validTo
+	case req.ValidTo != nil:
+		validTo, err := time.Parse("01-2006", *req.ValidTo)
+		if err != nil {
+			return entity.Discount{}, fmt.Errorf("invalid discount valid_to format: %w", err)
+		}
+		if validTo.Before(discount.ValidFrom) {
+			return entity.Discount{}, fmt.Errorf("invalid discount: valid_to must not be before valid_from")
+		}
+		discount.ValidTo = &validTo
+	}
+
+	return discount, nil
+}
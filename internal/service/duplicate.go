@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ListDuplicates группирует подписки одного плательщика на один сервис с пересекающимися периодами;
+// в группу попадают все подписки, связанные цепочкой пересечений
+func (s *subscriptionService) ListDuplicates(ctx context.Context, userID *uuid.UUID) ([]*entity.DuplicateGroup, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ListDuplicates")
+	defer span.End()
+
+	duplicates, err := s.repo.FindDuplicates(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Подписки отсортированы по пользователю, сервису и дате начала, поэтому группа продолжается,
+	// пока следующая подписка начинается не позже самого позднего конца уже собранных
+	groups := []*entity.DuplicateGroup{}
+	var group *entity.DuplicateGroup
+	var groupEnd *time.Time
+	for _, sub := range duplicates {
+		if group == nil || group.UserID != sub.UserID || group.ServiceID != sub.ServiceID ||
+			(groupEnd != nil && sub.StartDate.After(*groupEnd)) {
+			group = &entity.DuplicateGroup{UserID: sub.UserID, ServiceID: sub.ServiceID, ServiceName: sub.ServiceName}
+			groups = append(groups, group)
+			groupEnd = sub.EndDate
+		} else if groupEnd != nil && (sub.EndDate == nil || sub.EndDate.After(*groupEnd)) {
+			groupEnd = sub.EndDate
+		}
+		group.Subscriptions = append(group.Subscriptions, sub)
+	}
+
+	return groups, nil
+}
+
+// MergeSubscriptions вливает дубликаты в подписку id: ее период расширяется до объединения периодов,
+// теги объединяются, а цены, скидки и участники остаются ее собственными. Дубликаты удаляются.
+func (s *subscriptionService) MergeSubscriptions(ctx context.Context, id uuid.UUID, req *entity.MergeRequest) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.MergeSubscriptions")
+	defer span.End()
+
+	ctx = repository.WithPrimary(ctx)
+	target, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for _, duplicateID := range req.DuplicateIDs {
+		if duplicateID == id {
+			return nil, fmt.Errorf("invalid merge: subscription %s cannot be merged into itself", id)
+		}
+		if seen[duplicateID] {
+			return nil, fmt.Errorf("invalid merge: subscription %s is listed more than once", duplicateID)
+		}
+		seen[duplicateID] = true
+
+		duplicate, err := s.repo.GetByID(ctx, duplicateID)
+		if err != nil {
+			return nil, err
+		}
+		if duplicate.UserID != target.UserID || duplicate.ServiceID != target.ServiceID {
+			return nil, fmt.Errorf("invalid merge: subscription %s belongs to another user or service", duplicateID)
+		}
+
+		if duplicate.StartDate.Before(target.StartDate) {
+			target.StartDate = duplicate.StartDate
+		}
+		if target.EndDate != nil && (duplicate.EndDate == nil || duplicate.EndDate.After(*target.EndDate)) {
+			target.EndDate = duplicate.EndDate
+		}
+		target.Tags = append(target.Tags, duplicate.Tags...)
+	}
+	slices.Sort(target.Tags)
+	target.Tags = slices.Compact(target.Tags)
+
+	if err := s.repo.Merge(ctx, target, req.DuplicateIDs); err != nil {
+		return nil, err
+	}
+
+	// Объединенная подписка может действовать дольше каждой из исходных
+	merged, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.checkBudgets(ctx, merged)
+	return merged, nil
+}
+
+// checkOverlaps ищет подписки того же пользователя на тот же сервис, пересекающиеся с candidate по периоду.
+// При политике reject пересечение — ошибка, при warn возвращаются предупреждения.
+func (s *subscriptionService) checkOverlaps(ctx context.Context, candidate *entity.Subscription) ([]string, error) {
+	found, err := s.repo.FindOverlapping(repository.WithPrimary(ctx), candidate.UserID, candidate.ServiceID, candidate.StartDate, candidate.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	found = slices.DeleteFunc(found, func(sub *entity.Subscription) bool { return sub.ID == candidate.ID })
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(found))
+	for i, sub := range found {
+		ids[i] = sub.ID.String()
+	}
+	if s.cfg.OverlapPolicy == config.OverlapReject {
+		return nil, fmt.Errorf("subscription overlaps with %s: same user, service and period", strings.Join(ids, ", "))
+	}
+
+	logger.FromContext(ctx).WithField("overlaps", ids).Warn("Subscription overlaps with existing subscriptions")
+	return []string{fmt.Sprintf("overlaps with subscriptions of the same user and service: %s", strings.Join(ids, ", "))}, nil
+}
+
+// exclusive сообщает, что новые периоды подписок защищаются от пересечений ограничением в базе.
+// Проверка checkOverlaps не видит параллельных запросов, ограничение ловит их при записи.
+func (s *subscriptionService) exclusive() bool {
+	return s.cfg.OverlapPolicy == config.OverlapReject
+}
+
+// checkUpdateOverlaps проверяет пересечения подписки id после изменения сервиса или дат из req
+func (s *subscriptionService) checkUpdateOverlaps(ctx context.Context, id uuid.UUID, req *entity.UpdateSubscriptionRequest) error {
+	candidate, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	if req.ServiceID != nil {
+		candidate.ServiceID = *req.ServiceID
+	}
+	if req.StartDate != nil {
+		if candidate.StartDate, err = entity.ParseDate(*req.StartDate); err != nil {
+			return fmt.Errorf("invalid start_date format: %w", err)
+		}
+	}
+	if req.EndDate != nil {
+		candidate.EndDate = nil
+		if *req.EndDate != "" {
+			endDate, err := entity.ParseEndDate(*req.EndDate)
+			if err != nil {
+				return fmt.Errorf("invalid end_date format: %w", err)
+			}
+			candidate.EndDate = &endDate
+		}
+	}
+
+	_, err = s.checkOverlaps(ctx, candidate)
+	return err
+}
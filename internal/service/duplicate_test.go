@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+)
+
+func TestCreateSubscriptionOverlaps(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  []period
+		candidate period
+		overlaps  bool
+	}{
+		{
+			name:      "нет других подписок",
+			candidate: period{"Netflix", "2026-01-01", ""},
+		},
+		{
+			name:      "другой сервис",
+			existing:  []period{{"Spotify", "2026-01-01", ""}},
+			candidate: period{"Netflix", "2026-01-01", ""},
+		},
+		{
+			name:      "вплотную после окончания",
+			existing:  []period{{"Netflix", "2026-01-01", "2026-03-31"}},
+			candidate: period{"Netflix", "2026-04-01", ""},
+		},
+		{
+			name:      "вплотную до начала",
+			existing:  []period{{"Netflix", "2026-04-01", ""}},
+			candidate: period{"Netflix", "2026-01-01", "2026-03-31"},
+		},
+		{
+			name:      "общий последний день",
+			existing:  []period{{"Netflix", "2026-01-01", "2026-03-31"}},
+			candidate: period{"Netflix", "2026-03-31", ""},
+			overlaps:  true,
+		},
+		{
+			name:      "внутри бессрочной",
+			existing:  []period{{"Netflix", "2025-06-01", ""}},
+			candidate: period{"Netflix", "2026-01-01", "2026-02-28"},
+			overlaps:  true,
+		},
+		{
+			name:      "бессрочная поверх будущей",
+			existing:  []period{{"Netflix", "2027-01-01", "2027-12-31"}},
+			candidate: period{"Netflix", "2026-01-01", ""},
+			overlaps:  true,
+		},
+		{
+			name:      "месяцы вместо дат",
+			existing:  []period{{"Netflix", "01-2026", "03-2026"}},
+			candidate: period{"Netflix", "03-2026", "05-2026"},
+			overlaps:  true,
+		},
+	}
+
+	for _, policy := range []string{config.OverlapWarn, config.OverlapReject} {
+		for _, tt := range tests {
+			t.Run(policy+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				svc, userID := newTestService(t, policy)
+				for _, p := range tt.existing {
+					if _, err := svc.SubscriptionService.CreateSubscription(ctx, p.request(userID)); err != nil {
+						t.Fatalf("create existing: %v", err)
+					}
+				}
+
+				sub, err := svc.SubscriptionService.CreateSubscription(ctx, tt.candidate.request(userID))
+				switch {
+				case !tt.overlaps:
+					if err != nil || len(sub.Warnings) != 0 {
+						t.Fatalf("got err = %v, warnings = %v; want no overlap", err, sub.Warnings)
+					}
+					if sub.Exclusive != (policy == config.OverlapReject) {
+						t.Errorf("Exclusive = %v under %s", sub.Exclusive, policy)
+					}
+				case policy == config.OverlapReject:
+					if err == nil || !strings.HasPrefix(err.Error(), "subscription overlaps") {
+						t.Fatalf("err = %v, want overlap error", err)
+					}
+				default:
+					if err != nil || len(sub.Warnings) != 1 {
+						t.Fatalf("got err = %v, warnings = %v; want one warning", err, sub.Warnings)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestUpdateSubscriptionOverlaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      entity.UpdateSubscriptionRequest
+		overlaps bool
+	}{
+		{name: "сдвиг начала в пересечение", req: entity.UpdateSubscriptionRequest{StartDate: ptr("2026-03-15")}, overlaps: true},
+		{name: "сдвиг начала без пересечения", req: entity.UpdateSubscriptionRequest{StartDate: ptr("2026-04-15")}},
+		{name: "бессрочное окончание", req: entity.UpdateSubscriptionRequest{EndDate: ptr("")}},
+		{name: "только цена", req: entity.UpdateSubscriptionRequest{Price: ptr(200)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, userID := newTestService(t, config.OverlapReject)
+			for _, p := range []period{{"Netflix", "2026-01-01", "2026-03-31"}} {
+				if _, err := svc.SubscriptionService.CreateSubscription(ctx, p.request(userID)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			later, err := svc.SubscriptionService.CreateSubscription(ctx, period{"Netflix", "2026-05-01", "2026-06-30"}.request(userID))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = svc.SubscriptionService.UpdateSubscription(ctx, later.ID, &tt.req)
+			if tt.overlaps {
+				if err == nil || !strings.HasPrefix(err.Error(), "subscription overlaps") {
+					t.Fatalf("err = %v, want overlap error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+		})
+	}
+}
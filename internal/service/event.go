@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type EventService interface {
+	ListEvents(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Event, error)
+	// RemindTrialsEnding создает напоминания о пробных периодах, после которых в течение before
+	// начнется оплата, и возвращает число новых событий
+	RemindTrialsEnding(ctx context.Context, now time.Time, before time.Duration) (int, error)
+}
+
+type eventService struct {
+	repo          repository.EventRepository
+	subscriptions repository.SubscriptionRepository
+}
+
+func NewEventService(repo repository.EventRepository, subscriptions repository.SubscriptionRepository) EventService {
+	return &eventService{repo: repo, subscriptions: subscriptions}
+}
+
+func (s *eventService) ListEvents(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Event, error) {
+	ctx, span := tracer.Start(ctx, "EventService.ListEvents")
+	defer span.End()
+
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.ListByUser(ctx, userID, limit, offset)
+}
+
+func (s *eventService) RemindTrialsEnding(ctx context.Context, now time.Time, before time.Duration) (int, error) {
+	ctx, span := tracer.Start(ctx, "EventService.RemindTrialsEnding")
+	defer span.End()
+
+	// Оплата начинается первого числа месяца после trial_end_date: напоминаем о периодах,
+	// где это число уже наступит к now+before, но еще не наступило к now
+	from := entity.MonthStart(now)
+	to := entity.MonthStart(now.Add(before)).AddDate(0, -1, 0)
+	if to.Before(from) {
+		return 0, nil
+	}
+
+	subscriptions, err := s.subscriptions.TrialsEnding(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, sub := range subscriptions {
+		// Подписка, которая заканчивается вместе с пробным периодом, платной не станет
+		if sub.EndDate != nil && !sub.EndDate.After(*sub.TrialEndDate) {
+			continue
+		}
+
+		paidFrom := sub.TrialEndDate.AddDate(0, 1, 0)
+		event := &entity.Event{
+			ID:             uuid.New(),
+			Type:           entity.EventTrialEnding,
+			UserID:         sub.UserID,
+			SubscriptionID: &sub.ID,
+			Message: fmt.Sprintf("Trial of %s ends in %s, from %s the subscription costs %d",
+				sub.ServiceName, sub.TrialEndDate.Format("01-2006"), paidFrom.Format("01-2006"), sub.PriceAt(paidFrom)),
+			DedupKey:  fmt.Sprintf("%s:%s:%s", entity.EventTrialEnding, sub.ID, sub.TrialEndDate.Format("2006-01")),
+			CreatedAt: now.UTC(),
+		}
+
+		ok, err := s.repo.Create(ctx, event)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"user_id":         event.UserID,
+				"subscription_id": sub.ID,
+			}).Info(event.Message)
+		}
+	}
+
+	return created, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultForecastMonths — горизонт прогноза, если months не задан
+const defaultForecastMonths = 12
+
+// GetForecast прогнозирует расходы на months месяцев вперед начиная со следующего месяца.
+// Каждый месяц считается так же, как summary: с окончанием подписок, запланированными ценами,
+// пробными периодами и скидками; с user_id — только доля пользователя.
+func (s *subscriptionService) GetForecast(ctx context.Context, req *entity.ForecastRequest) (*entity.Forecast, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetForecast")
+	defer span.End()
+
+	months := req.Months
+	if months == 0 {
+		months = defaultForecastMonths
+	}
+
+	from := entity.MonthStart(time.Now()).AddDate(0, 1, 0)
+	window := billingWindow{entity.SummaryWindow{From: from, To: from.AddDate(0, months-1, 0)}}
+	startPeriod, endPeriod := window.From.Format("01-2006"), window.To.Format("01-2006")
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id":      req.UserID,
+		"service_name": req.ServiceName,
+		"start_period": startPeriod,
+		"end_period":   endPeriod,
+		"cancel":       req.Cancel,
+	}).Debug("Calculating spending forecast")
+
+	summaryReq := &entity.SubscriptionSummaryRequest{
+		UserID:      req.UserID,
+		ServiceName: req.ServiceName,
+		StartPeriod: &startPeriod,
+		EndPeriod:   &endPeriod,
+		Tags:        req.Tags,
+	}
+	if err := s.prepareSummary(ctx, summaryReq); err != nil {
+		return nil, err
+	}
+
+	totals := newForecastTotals(window)
+	if req.UserID == nil {
+		// Личные подписки целиком приходятся на плательщика, их хранилище считает само;
+		// в Go остаются только совместные, которые надо делить между участниками
+		personal, shared := false, true
+		personalReq := *summaryReq
+		personalReq.Shared = &personal
+		spend, err := s.repo.MonthlySpend(ctx, &personalReq, req.Cancel)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range spend {
+			totals.add(m.Month, m.ServiceName, map[uuid.UUID]int{m.UserID: m.Cost}, m.Cancelled)
+		}
+		summaryReq.Shared = &shared
+	}
+
+	subscriptions, err := s.repo.Find(ctx, summaryReq)
+	if err != nil {
+		return nil, err
+	}
+	window.forecast(totals, subscriptions, req.UserID, req.Cancel)
+
+	return totals.result(), nil
+}
+
+// forecast раскладывает стоимость подписок по месяцам окна, пользователям и сервисам.
+// Подписки из cancel в расходы не входят, их стоимость попадает в Savings.
+func (w billingWindow) forecast(totals *forecastTotals, subscriptions []*entity.Subscription, userID *uuid.UUID, cancel []uuid.UUID) {
+	for _, s := range subscriptions {
+		cancelled := slices.Contains(cancel, s.ID)
+		w.Charges(s, func(month time.Time, price int) {
+			shares := s.Shares(price)
+			if userID != nil {
+				shares = map[uuid.UUID]int{*userID: shares[*userID]}
+			}
+			totals.add(month, s.ServiceName, shares, cancelled)
+		})
+	}
+}
+
+// forecastTotals накапливает прогноз по месяцам окна
+type forecastTotals struct {
+	forecast *entity.Forecast
+	months   map[time.Time]*entity.ForecastMonth
+	users    map[time.Time]map[uuid.UUID]int
+	services map[time.Time]map[string]int
+}
+
+func newForecastTotals(w billingWindow) *forecastTotals {
+	t := &forecastTotals{
+		forecast: &entity.Forecast{
+			StartPeriod: w.From.Format("01-2006"),
+			EndPeriod:   w.To.Format("01-2006"),
+			Months:      []*entity.ForecastMonth{},
+		},
+		months:   make(map[time.Time]*entity.ForecastMonth),
+		users:    make(map[time.Time]map[uuid.UUID]int),
+		services: make(map[time.Time]map[string]int),
+	}
+	for month := w.From; !month.After(w.To); month = month.AddDate(0, 1, 0) {
+		m := &entity.ForecastMonth{Month: month.Format("01-2006")}
+		t.months[month] = m
+		t.forecast.Months = append(t.forecast.Months, m)
+		t.users[month] = make(map[uuid.UUID]int)
+		t.services[month] = make(map[string]int)
+	}
+	return t
+}
+
+// add учитывает стоимость подписки на сервис в месяце month, разделенную между пользователями shares;
+// стоимость отмененной подписки идет в Savings
+func (t *forecastTotals) add(month time.Time, serviceName string, shares map[uuid.UUID]int, cancelled bool) {
+	cost := 0
+	for _, share := range shares {
+		cost += share
+	}
+
+	m := t.months[month]
+	if cancelled {
+		m.Savings += cost
+		return
+	}
+	m.TotalCost += cost
+	for id, share := range shares {
+		if share > 0 {
+			t.users[month][id] += share
+		}
+	}
+	t.services[month][serviceName] += cost
+}
+
+// result сортирует пользователей и сервисы месяцев и подводит итоги прогноза
+func (t *forecastTotals) result() *entity.Forecast {
+	for month, m := range t.months {
+		m.Users = []*entity.UserCost{}
+		for id, cost := range t.users[month] {
+			m.Users = append(m.Users, &entity.UserCost{UserID: id, Cost: cost})
+		}
+		sort.Slice(m.Users, func(i, j int) bool { return m.Users[i].UserID.String() < m.Users[j].UserID.String() })
+
+		m.Services = []*entity.ServiceCost{}
+		for name, cost := range t.services[month] {
+			m.Services = append(m.Services, &entity.ServiceCost{ServiceName: name, Cost: cost})
+		}
+		sort.Slice(m.Services, func(i, j int) bool { return m.Services[i].ServiceName < m.Services[j].ServiceName })
+
+		t.forecast.TotalCost += m.TotalCost
+		t.forecast.Savings += m.Savings
+	}
+
+	return t.forecast
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestGetForecast(t *testing.T) {
+	ctx := context.Background()
+	svc, alice := newTestService(t, config.OverlapWarn)
+	bobUser, err := svc.UserService.CreateUser(ctx, &entity.CreateUserRequest{DisplayName: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob := bobUser.ID
+
+	next := entity.MonthStart(time.Now()).AddDate(0, 1, 0)
+	month := func(n int) string { return next.AddDate(0, n, 0).Format(entity.MonthLayout) }
+	create := func(req *entity.CreateSubscriptionRequest) *entity.Subscription {
+		sub, err := svc.SubscriptionService.CreateSubscription(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sub
+	}
+
+	// Личная подписка Alice, которую прогноз отменяет
+	netflix := create(&entity.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 300, UserID: alice, StartDate: month(-1)})
+	// Совместная: Alice платит треть, Bob — две трети
+	create(&entity.CreateSubscriptionRequest{
+		ServiceName: "Spotify", Price: 600, UserID: alice, StartDate: month(-1),
+		Participants: []entity.Participant{{UserID: alice, Weight: ptr(1)}, {UserID: bob, Weight: ptr(2)}},
+	})
+	// Личная подписка Bob с пробным вторым месяцем прогноза
+	create(&entity.CreateSubscriptionRequest{
+		ServiceName: "Kinopoisk", Price: 100, UserID: bob, StartDate: month(1), TrialEndDate: ptr(month(1)),
+	})
+
+	type monthWant struct {
+		total, savings int
+		users          map[uuid.UUID]int
+		services       map[string]int
+	}
+	tests := []struct {
+		name           string
+		req            entity.ForecastRequest
+		total, savings int
+		months         []monthWant
+	}{
+		{
+			name:  "все пользователи с отменой",
+			req:   entity.ForecastRequest{Months: 3, Cancel: []uuid.UUID{netflix.ID}},
+			total: 1900, savings: 900,
+			months: []monthWant{
+				{600, 300, map[uuid.UUID]int{alice: 200, bob: 400}, map[string]int{"Spotify": 600}},
+				{600, 300, map[uuid.UUID]int{alice: 200, bob: 400}, map[string]int{"Spotify": 600}},
+				{700, 300, map[uuid.UUID]int{alice: 200, bob: 500}, map[string]int{"Kinopoisk": 100, "Spotify": 600}},
+			},
+		},
+		{
+			name:  "доля пользователя",
+			req:   entity.ForecastRequest{Months: 3, UserID: &bob},
+			total: 1300,
+			months: []monthWant{
+				{400, 0, map[uuid.UUID]int{bob: 400}, map[string]int{"Spotify": 400}},
+				{400, 0, map[uuid.UUID]int{bob: 400}, map[string]int{"Spotify": 400}},
+				{500, 0, map[uuid.UUID]int{bob: 500}, map[string]int{"Kinopoisk": 100, "Spotify": 400}},
+			},
+		},
+		{
+			name:  "без отмены",
+			req:   entity.ForecastRequest{Months: 1, ServiceName: ptr("netflix")},
+			total: 300,
+			months: []monthWant{
+				{300, 0, map[uuid.UUID]int{alice: 300}, map[string]int{"Netflix": 300}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast, err := svc.SubscriptionService.GetForecast(ctx, &tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if forecast.TotalCost != tt.total || forecast.Savings != tt.savings {
+				t.Errorf("total = %d, savings = %d; want %d, %d", forecast.TotalCost, forecast.Savings, tt.total, tt.savings)
+			}
+			if forecast.StartPeriod != month(0) || len(forecast.Months) != len(tt.months) {
+				t.Fatalf("start = %s, months = %d; want %s, %d", forecast.StartPeriod, len(forecast.Months), month(0), len(tt.months))
+			}
+
+			for i, want := range tt.months {
+				got := forecast.Months[i]
+				users := make(map[uuid.UUID]int)
+				for _, u := range got.Users {
+					users[u.UserID] = u.Cost
+				}
+				services := make(map[string]int)
+				for _, s := range got.Services {
+					services[s.ServiceName] = s.Cost
+				}
+				if got.Month != month(i) || got.TotalCost != want.total || got.Savings != want.savings ||
+					fmt.Sprint(users) != fmt.Sprint(want.users) || fmt.Sprint(services) != fmt.Sprint(want.services) {
+					t.Errorf("month %d = %s total %d savings %d users %v services %v; want %s total %d savings %d users %v services %v",
+						i, got.Month, got.TotalCost, got.Savings, users, services,
+						month(i), want.total, want.savings, want.users, want.services)
+				}
+			}
+		})
+	}
+}
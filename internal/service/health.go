@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/migrations"
+)
+
+// checkTimeout ограничивает время одной проверки готовности,
+// чтобы зависшая база не блокировала пробу оркестратора.
+const checkTimeout = 2 * time.Second
+
+type HealthService interface {
+	Liveness(ctx context.Context) *entity.HealthReport
+	Readiness(ctx context.Context) *entity.HealthReport
+	SetShuttingDown()
+}
+
+type healthService struct {
+	repo         repository.HealthRepository
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(repo repository.HealthRepository) HealthService {
+	return &healthService{repo: repo}
+}
+
+func (s *healthService) Liveness(ctx context.Context) *entity.HealthReport {
+	return &entity.HealthReport{Status: entity.HealthStatusOK}
+}
+
+func (s *healthService) Readiness(ctx context.Context) *entity.HealthReport {
+	report := &entity.HealthReport{
+		Status: entity.HealthStatusOK,
+		Checks: map[string]*entity.HealthCheck{
+			"database":   s.check(ctx, s.checkDatabase),
+			"migrations": s.check(ctx, s.checkMigrations),
+		},
+	}
+
+	if s.repo.HasReplica() {
+		report.Checks["replica"] = s.check(ctx, s.repo.PingReplica)
+	}
+
+	if s.shuttingDown.Load() {
+		report.Checks["shutdown"] = &entity.HealthCheck{
+			Status: entity.HealthStatusUnavailable,
+			Error:  "server is shutting down",
+		}
+	}
+
+	for _, check := range report.Checks {
+		if check.Status != entity.HealthStatusOK {
+			report.Status = entity.HealthStatusUnavailable
+		}
+	}
+
+	return report
+}
+
+// SetShuttingDown переводит сервис в состояние «не готов» на время graceful shutdown
+func (s *healthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *healthService) check(ctx context.Context, fn func(ctx context.Context) error) *entity.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	check := &entity.HealthCheck{
+		Status:    entity.HealthStatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		check.Status = entity.HealthStatusUnavailable
+		check.Error = err.Error()
+	}
+
+	return check
+}
+
+func (s *healthService) checkDatabase(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
+
+func (s *healthService) checkMigrations(ctx context.Context) error {
+	version, dirty, err := s.repo.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if expected := migrations.LatestVersion(); version != expected {
+		return fmt.Errorf("migration version %d, expected %d", version, expected)
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// CancelSubscription завершает подписку днем effective_date (по умолчанию сегодня) и сохраняет причину отмены
+func (s *subscriptionService) CancelSubscription(ctx context.Context, id uuid.UUID, req *entity.CancelRequest) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.CancelSubscription")
+	defer span.End()
+
+	ctx = repository.WithPrimary(ctx)
+	subscription, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.CancelReason != nil {
+		return nil, fmt.Errorf("invalid cancellation: subscription is already cancelled")
+	}
+
+	now := today()
+	endDate := now
+	if req.EffectiveDate != nil {
+		if endDate, err = entity.ParseEndDate(*req.EffectiveDate); err != nil {
+			return nil, fmt.Errorf("invalid cancellation: effective_date: %w", err)
+		}
+	}
+	if endDate.Before(now) {
+		return nil, fmt.Errorf("invalid cancellation: effective_date is in the past")
+	}
+	if endDate.Before(subscription.StartDate) {
+		return nil, fmt.Errorf("invalid cancellation: effective_date is before start_date")
+	}
+	if subscription.EndDate != nil && endDate.After(*subscription.EndDate) {
+		return nil, fmt.Errorf("invalid cancellation: subscription already ends on %s", subscription.EndDate.Format(entity.DateLayout))
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": id,
+		"end_date":        endDate.Format(entity.DateLayout),
+		"reason":          req.Reason,
+	}).Info("Cancelling subscription")
+
+	if err := s.repo.Cancel(ctx, id, endDate, req.Reason, req.Comment, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// PauseSubscription приостанавливает подписку с effective_date (по умолчанию сегодня) до возобновления.
+// Дни на паузе не оплачиваются.
+func (s *subscriptionService) PauseSubscription(ctx context.Context, id uuid.UUID, req *entity.LifecycleRequest) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.PauseSubscription")
+	defer span.End()
+
+	ctx = repository.WithPrimary(ctx)
+	subscription, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := lifecycleDate(req.EffectiveDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pause: %w", err)
+	}
+	if startDate.Before(subscription.StartDate) {
+		return nil, fmt.Errorf("invalid pause: effective_date is before start_date")
+	}
+	if subscription.EndDate != nil && startDate.After(*subscription.EndDate) {
+		return nil, fmt.Errorf("invalid pause: subscription ends on %s", subscription.EndDate.Format(entity.DateLayout))
+	}
+	// Новая пауза открыта, поэтому пересекается с любой паузой, которая еще не закончилась к ее началу
+	for _, p := range subscription.Pauses {
+		if p.EndDate == nil {
+			return nil, fmt.Errorf("invalid pause: subscription is already paused since %s", p.StartDate.Format(entity.DateLayout))
+		}
+		if !p.EndDate.Before(startDate) {
+			return nil, fmt.Errorf("invalid pause: overlaps pause until %s", p.EndDate.Format(entity.DateLayout))
+		}
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": id,
+		"start_date":      startDate.Format(entity.DateLayout),
+	}).Info("Pausing subscription")
+
+	pause := entity.Pause{ID: uuid.New(), StartDate: startDate}
+	if err := s.repo.AddPause(ctx, id, pause); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ResumeSubscription возобновляет подписку с effective_date (по умолчанию сегодня): пауза заканчивается
+// накануне. Если пауза еще не началась, она отменяется целиком.
+func (s *subscriptionService) ResumeSubscription(ctx context.Context, id uuid.UUID, req *entity.LifecycleRequest) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ResumeSubscription")
+	defer span.End()
+
+	ctx = repository.WithPrimary(ctx)
+	subscription, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pause := subscription.OpenPause()
+	if pause == nil {
+		return nil, fmt.Errorf("invalid resume: subscription is not paused")
+	}
+
+	resumeDate, err := lifecycleDate(req.EffectiveDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resume: %w", err)
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": id,
+		"pause_id":        pause.ID,
+		"resume_date":     resumeDate.Format(entity.DateLayout),
+	}).Info("Resuming subscription")
+
+	if resumeDate.After(pause.StartDate) {
+		err = s.repo.EndPause(ctx, id, pause.ID, resumeDate.AddDate(0, 0, -1))
+	} else {
+		err = s.repo.DeletePause(ctx, id, pause.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resumed, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.checkBudgets(ctx, resumed)
+	return resumed, nil
+}
+
+// GetCancellationReport группирует отмененные подписки, закончившиеся в периоде, по причине отмены.
+// MonthlySavings — сколько эти подписки стоили бы в месяц окончания.
+func (s *subscriptionService) GetCancellationReport(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.CancellationStats, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetCancellationReport")
+	defer span.End()
+
+	if err := s.prepareSummary(ctx, req); err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := s.repo.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Форматы уже проверены в prepareSummary. Find фильтрует по пересечению с периодом,
+	// а в отчет попадают только подписки, закончившиеся внутри него
+	var startPeriod, endPeriod *time.Time
+	if req.StartPeriod != nil {
+		from, _ := time.Parse("01-2006", *req.StartPeriod)
+		startPeriod = &from
+	}
+	if req.EndPeriod != nil {
+		to, _ := time.Parse("01-2006", *req.EndPeriod)
+		to = entity.MonthEnd(to)
+		endPeriod = &to
+	}
+
+	byReason := map[string]*entity.CancellationStats{}
+	for _, sub := range subscriptions {
+		if sub.CancelReason == nil || sub.EndDate == nil {
+			continue
+		}
+		if startPeriod != nil && sub.EndDate.Before(*startPeriod) || endPeriod != nil && sub.EndDate.After(*endPeriod) {
+			continue
+		}
+
+		stats, ok := byReason[*sub.CancelReason]
+		if !ok {
+			stats = &entity.CancellationStats{Reason: *sub.CancelReason}
+			byReason[*sub.CancelReason] = stats
+		}
+		stats.Count++
+		stats.MonthlySavings += sub.EffectivePriceAt(entity.MonthStart(*sub.EndDate))
+	}
+
+	report := make([]*entity.CancellationStats, 0, len(byReason))
+	for _, stats := range byReason {
+		report = append(report, stats)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Count != report[j].Count {
+			return report[i].Count > report[j].Count
+		}
+		return report[i].Reason < report[j].Reason
+	})
+
+	return report, nil
+}
+
+// lifecycleDate разбирает дату паузы или возобновления; по умолчанию сегодня, прошлые даты запрещены
+func lifecycleDate(value *string) (time.Time, error) {
+	now := today()
+	if value == nil {
+		return now, nil
+	}
+	date, err := entity.ParseDate(*value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("effective_date: %w", err)
+	}
+	if date.Before(now) {
+		return time.Time{}, fmt.Errorf("effective_date is in the past")
+	}
+	return date, nil
+}
+
+// today возвращает текущую дату в UTC без времени
+func today() time.Time {
+	return entity.DateOf(time.Now())
+}
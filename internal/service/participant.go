@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/google/uuid"
+)
+
+// GetSettlement считает, сколько участники совместных подписок должны плательщикам за период.
+// Встречные долги двух пользователей взаимозачитываются; с user_id остаются только его долги и долги ему.
+func (s *subscriptionService) GetSettlement(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.Debt, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetSettlement")
+	defer span.End()
+
+	if err := s.prepareSummary(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// Долги возникают только в совместных подписках
+	shared := true
+	req.Shared = &shared
+	subscriptions, err := s.repo.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	type pair struct{ from, to uuid.UUID }
+	owed := make(map[pair]int)
+	window := newBillingWindow(req)
+	for _, sub := range subscriptions {
+		window.Charges(sub, func(month time.Time, price int) {
+			for userID, share := range sub.Shares(price) {
+				if userID != sub.UserID && share > 0 {
+					owed[pair{userID, sub.UserID}] += share
+				}
+			}
+		})
+	}
+
+	debts := []*entity.Debt{}
+	for p, amount := range owed {
+		net := amount - owed[pair{p.to, p.from}]
+		if net <= 0 {
+			continue
+		}
+		if req.UserID != nil && p.from != *req.UserID && p.to != *req.UserID {
+			continue
+		}
+		debts = append(debts, &entity.Debt{FromUserID: p.from, ToUserID: p.to, Amount: net})
+	}
+	sort.Slice(debts, func(i, j int) bool {
+		if debts[i].FromUserID != debts[j].FromUserID {
+			return debts[i].FromUserID.String() < debts[j].FromUserID.String()
+		}
+		return debts[i].ToUserID.String() < debts[j].ToUserID.String()
+	})
+
+	return debts, nil
+}
+
+// normalizeParticipants проверяет участников подписки с плательщиком payer: фиксированные суммы
+// должны укладываться в каждую из цен prices. Участники сортируются по user_id, как при чтении из базы.
+func (s *subscriptionService) normalizeParticipants(ctx context.Context, payer uuid.UUID, prices []int, participants []entity.Participant) ([]entity.Participant, error) {
+	normalized := make([]entity.Participant, 0, len(participants))
+	seen := make(map[uuid.UUID]bool, len(participants))
+	fixed := 0
+	for _, p := range participants {
+		if (p.Weight == nil) == (p.Amount == nil) {
+			return nil, fmt.Errorf("invalid participant %s: exactly one of weight and amount is required", p.UserID)
+		}
+		if seen[p.UserID] {
+			return nil, fmt.Errorf("invalid participant %s: listed more than once", p.UserID)
+		}
+		seen[p.UserID] = true
+
+		if p.UserID != payer {
+			if _, err := s.users.GetByID(repository.WithPrimary(ctx), p.UserID); err != nil {
+				if err.Error() == "user not found" {
+					return nil, fmt.Errorf("invalid participant %s: user not found", p.UserID)
+				}
+				return nil, err
+			}
+		}
+		if p.Amount != nil {
+			fixed += *p.Amount
+		}
+		normalized = append(normalized, p)
+	}
+
+	for _, price := range prices {
+		if fixed > price {
+			return nil, fmt.Errorf("invalid participant amounts: %d exceeds price %d", fixed, price)
+		}
+	}
+
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i].UserID.String() < normalized[j].UserID.String() })
+	return normalized, nil
+}
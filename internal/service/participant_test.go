@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+)
+
+func TestGetSettlement(t *testing.T) {
+	ctx := context.Background()
+	svc, alice := newTestService(t, config.OverlapWarn)
+	user, err := svc.UserService.CreateUser(ctx, &entity.CreateUserRequest{DisplayName: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob := user.ID
+
+	// Alice платит 300 в месяц и делит поровну с Bob; Bob платит 90 в месяц после двух пробных, из них Alice — 30
+	if _, err := svc.SubscriptionService.CreateSubscription(ctx, &entity.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 300, UserID: alice, StartDate: "2025-01-01",
+		Participants: []entity.Participant{{UserID: alice, Weight: ptr(1)}, {UserID: bob, Weight: ptr(1)}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SubscriptionService.CreateSubscription(ctx, &entity.CreateSubscriptionRequest{
+		ServiceName: "Spotify", Price: 90, UserID: bob, StartDate: "2025-01-15", TrialMonths: ptr(2),
+		Participants: []entity.Participant{{UserID: alice, Amount: ptr(30)}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     int
+	}{
+		// 12 × 150 − 10 × 30
+		{name: "год", from: "01-2025", to: "12-2025", want: 1500},
+		// 6 × 150 − 4 × 30
+		{name: "полгода", from: "01-2025", to: "06-2025", want: 780},
+		// Только пробные месяцы Spotify
+		{name: "один месяц", from: "02-2025", to: "02-2025", want: 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debts, err := svc.SubscriptionService.GetSettlement(ctx, &entity.SubscriptionSummaryRequest{
+				StartPeriod: &tt.from, EndPeriod: &tt.to, UserID: &alice,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(debts) != 1 || debts[0].FromUserID != bob || debts[0].ToUserID != alice || debts[0].Amount != tt.want {
+				t.Fatalf("debts = %+v, want bob owes alice %d", debts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/google/uuid"
+)
+
+// SchedulePrice назначает новую цену с месяца req.EffectiveFrom и возвращает подписку с историей цен.
+// Прошлые месяцы изменить нельзя: summary за них уже посчитан по действовавшей цене.
+func (s *subscriptionService) SchedulePrice(ctx context.Context, id uuid.UUID, req *entity.SchedulePriceRequest) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.SchedulePrice")
+	defer span.End()
+
+	effectiveFrom, err := time.Parse("01-2006", req.EffectiveFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective_from format: %w", err)
+	}
+
+	current, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if effectiveFrom.Before(entity.MonthStart(time.Now())) {
+		return nil, fmt.Errorf("invalid effective_from: price changes can only be scheduled from the current month")
+	}
+	if effectiveFrom.Before(entity.MonthStart(current.StartDate)) {
+		return nil, fmt.Errorf("invalid effective_from: subscription starts in %s", current.StartDate.Format("01-2006"))
+	}
+	if current.EndDate != nil && effectiveFrom.After(*current.EndDate) {
+		return nil, fmt.Errorf("invalid effective_from: subscription ends in %s", current.EndDate.Format("01-2006"))
+	}
+
+	if _, err := s.normalizeParticipants(ctx, current.UserID, []int{req.Price}, current.Participants); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetPrice(ctx, id, entity.PricePeriod{EffectiveFrom: effectiveFrom, Price: req.Price}); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	s.checkBudgets(ctx, updated)
+	return updated, nil
+}
+
+// CancelPriceChange отменяет запланированное изменение цены; начальную и прошлые цены удалить нельзя
+func (s *subscriptionService) CancelPriceChange(ctx context.Context, id uuid.UUID, effectiveFrom string) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.CancelPriceChange")
+	defer span.End()
+
+	month, err := time.Parse("01-2006", effectiveFrom)
+	if err != nil {
+		return fmt.Errorf("invalid effective_from format: %w", err)
+	}
+
+	current, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+	if len(current.Prices) > 0 && current.Prices[0].EffectiveFrom.Equal(month) {
+		return fmt.Errorf("invalid effective_from: the initial price can not be removed")
+	}
+	if month.Before(entity.MonthStart(time.Now())) {
+		return fmt.Errorf("invalid effective_from: past prices can not be removed")
+	}
+
+	return s.repo.DeletePrice(ctx, id, month)
+}
+
+// futurePrices возвращает цены, которые действуют с месяца now и позже
+func futurePrices(s *entity.Subscription, now time.Time) []int {
+	month := entity.MonthStart(now)
+	prices := []int{s.PriceAt(month)}
+	for _, p := range s.Prices {
+		if p.EffectiveFrom.After(month) {
+			prices = append(prices, p.Price)
+		}
+	}
+	return prices
+}
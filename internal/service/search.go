@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
+	"github.com/ShekleinAleksey/subscriptions/pkg/trigram"
+	"github.com/sirupsen/logrus"
+)
+
+// SearchSubscriptions ищет подписки по названию сервиса без учета регистра и с опечатками
+// и подсвечивает в названии совпавшие с запросом фрагменты
+func (s *subscriptionService) SearchSubscriptions(ctx context.Context, req *entity.SearchRequest) ([]*entity.SearchResult, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.SearchSubscriptions")
+	defer span.End()
+
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return nil, fmt.Errorf("invalid search query: q is empty")
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 50
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"query":   req.Query,
+		"user_id": req.UserID,
+	}).Debug("Searching subscriptions")
+
+	results, err := s.repo.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.Highlight = trigram.Highlight(result.Subscription.ServiceName, req.Query)
+	}
+	return results, nil
+}
@@ -1,13 +1,29 @@
 package service
 
-import "github.com/ShekleinAleksey/subscriptions/internal/repository"
+import (
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/ShekleinAleksey/subscriptions/internal/service")
 
 type Service struct {
 	SubscriptionService SubscriptionService
+	CatalogService      CatalogService
+	UserService         UserService
+	EventService        EventService
+	BudgetService       BudgetService
+	HealthService       HealthService
 }
 
-func NewService(r *repository.Repository) *Service {
+func NewService(r *repository.Repository, cfg config.Subscriptions) *Service {
 	return &Service{
-		SubscriptionService: NewSubscriptionService(r.SubscriptionRepository),
+		SubscriptionService: NewSubscriptionService(r.SubscriptionRepository, r.CatalogRepository, r.UserRepository, r.BudgetRepository, r.EventRepository, cfg),
+		CatalogService:      NewCatalogService(r.CatalogRepository),
+		UserService:         NewUserService(r.UserRepository),
+		EventService:        NewEventService(r.EventRepository, r.SubscriptionRepository),
+		BudgetService:       NewBudgetService(r.BudgetRepository, r.SubscriptionRepository, r.CatalogRepository, r.UserRepository),
+		HealthService:       NewHealthService(r.HealthRepository),
 	}
 }
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/google/uuid"
+)
+
+// newTestService создает сервис поверх хранилища в памяти и пользователя для подписок
+func newTestService(t *testing.T, policy string) (*Service, uuid.UUID) {
+	t.Helper()
+
+	repo, err := repository.NewMemoryRepository(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewService(repo, config.Subscriptions{OverlapPolicy: policy})
+
+	user, err := svc.UserService.CreateUser(context.Background(), &entity.CreateUserRequest{DisplayName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc, user.ID
+}
+
+type period struct {
+	service string
+	start   string
+	end     string
+}
+
+func (p period) request(userID uuid.UUID) *entity.CreateSubscriptionRequest {
+	req := &entity.CreateSubscriptionRequest{ServiceName: p.service, Price: 100, UserID: userID, StartDate: p.start}
+	if p.end != "" {
+		req.EndDate = &p.end
+	}
+	return req
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestSubscriptionCRUD(t *testing.T) {
+	ctx := context.Background()
+	svc, userID := newTestService(t, config.OverlapWarn)
+	subscriptions := svc.SubscriptionService
+
+	created, err := subscriptions.CreateSubscription(ctx, &entity.CreateSubscriptionRequest{
+		ServiceName: "  yandex   PLUS ", Price: 299, UserID: userID, StartDate: "03-2026", Tags: []string{"Music"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := subscriptions.CreateSubscription(ctx, &entity.CreateSubscriptionRequest{
+		ServiceName: "Yandex Plus", Price: 299, UserID: userID, StartDate: "2026-09-15",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ServiceID != created.ServiceID {
+		t.Errorf("same service resolved to %s and %s", created.ServiceID, again.ServiceID)
+	}
+
+	if err := subscriptions.UpdateSubscription(ctx, created.ID, &entity.UpdateSubscriptionRequest{EndDate: ptr("05-2026")}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := subscriptions.GetSubscription(ctx, created.ID, ptr("2026-05-10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.EndDate == nil || got.EndDate.Format(entity.DateLayout) != "2026-05-31" {
+		t.Errorf("EndDate = %v, want 2026-05-31", got.EndDate)
+	}
+	if got.Status != entity.StatusEndingSoon || got.Price != 299 || len(got.Tags) != 1 || got.Tags[0] != "music" {
+		t.Errorf("got status %q, price %d, tags %v", got.Status, got.Price, got.Tags)
+	}
+
+	list, err := subscriptions.ListSubscriptions(ctx, &entity.ListSubscriptionsRequest{UserID: &userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != created.ID || list[1].ID != again.ID {
+		t.Errorf("list = %v, want both subscriptions by start date", list)
+	}
+
+	if err := subscriptions.DeleteSubscription(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := subscriptions.GetSubscription(ctx, created.ID, nil); err == nil || err.Error() != "subscription not found" {
+		t.Errorf("get after delete: err = %v", err)
+	}
+	if _, err := subscriptions.GetSubscription(ctx, uuid.New(), nil); err == nil {
+		t.Error("get unknown subscription: want error")
+	}
+}
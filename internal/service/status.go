@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+)
+
+// statusBatchSize — сколько подписок listByStatus читает за раз, когда статус уточняется после выборки
+const statusBatchSize = 500
+
+// parseAsOf разбирает день, на который вычисляются статусы; без него — сейчас
+func parseAsOf(value *string) (time.Time, error) {
+	if value == nil {
+		return time.Now(), nil
+	}
+	asOf, err := entity.ParseDate(*value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid as_of: %w", err)
+	}
+	return asOf, nil
+}
+
+func validateStatuses(statuses []string) error {
+	for _, status := range statuses {
+		if !entity.IsStatus(status) {
+			return fmt.Errorf("invalid status %q: expected one of %v", status, entity.Statuses)
+		}
+	}
+	return nil
+}
+
+// filterByStatus оставляет подписки, у которых на день asOf один из статусов statuses;
+// пустой statuses оставляет все. Статус считается тем же StatusAt, что и поле status в ответе.
+func filterByStatus(subscriptions []*entity.Subscription, statuses []string, asOf time.Time) []*entity.Subscription {
+	if len(statuses) == 0 {
+		return subscriptions
+	}
+
+	filtered := subscriptions[:0]
+	for _, sub := range subscriptions {
+		if slices.Contains(statuses, sub.StatusAt(asOf)) {
+			filtered = append(filtered, sub)
+		}
+	}
+	return filtered
+}
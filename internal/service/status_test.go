@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+)
+
+func TestListSubscriptionsByStatus(t *testing.T) {
+	ctx := context.Background()
+	svc, userID := newTestService(t, config.OverlapWarn)
+	subscriptions := svc.SubscriptionService
+
+	// На день asOf: 2 scheduled, 2 expired, 1 trialing, 1 ending_soon и 3 active
+	for _, req := range []*entity.CreateSubscriptionRequest{
+		{ServiceName: "A", StartDate: "2026-07-01"},
+		{ServiceName: "B", StartDate: "2026-08-01"},
+		{ServiceName: "C", StartDate: "2025-01-01", EndDate: ptr("2025-12-31")},
+		{ServiceName: "D", StartDate: "2025-02-01", EndDate: ptr("2026-05-31")},
+		{ServiceName: "E", StartDate: "2026-05-01", TrialMonths: ptr(2)},
+		{ServiceName: "F", StartDate: "2025-03-01", EndDate: ptr("2026-06-30")},
+		{ServiceName: "G", StartDate: "2025-04-01"},
+		{ServiceName: "H", StartDate: "2025-05-01", EndDate: ptr("2026-12-31")},
+		{ServiceName: "I", StartDate: "2025-06-01"},
+	} {
+		req.Price, req.UserID = 100, userID
+		if _, err := subscriptions.CreateSubscription(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		statuses []string
+		limit    int
+		offset   int
+		want     []string
+	}{
+		{name: "scheduled", statuses: []string{entity.StatusScheduled}, want: []string{"A", "B"}},
+		{name: "expired", statuses: []string{entity.StatusExpired}, want: []string{"C", "D"}},
+		{name: "trialing", statuses: []string{entity.StatusTrialing}, want: []string{"E"}},
+		{name: "ending_soon", statuses: []string{entity.StatusEndingSoon}, want: []string{"F"}},
+		{name: "active", statuses: []string{entity.StatusActive}, want: []string{"G", "H", "I"}},
+		{name: "несколько статусов", statuses: []string{entity.StatusExpired, entity.StatusTrialing}, want: []string{"C", "D", "E"}},
+		{name: "страница", statuses: []string{entity.StatusActive, entity.StatusEndingSoon}, limit: 2, offset: 1, want: []string{"G", "H"}},
+		{name: "страница точных статусов", statuses: []string{entity.StatusScheduled, entity.StatusExpired}, limit: 2, offset: 1, want: []string{"D", "A"}},
+		{name: "за концом списка", statuses: []string{entity.StatusActive}, offset: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := subscriptions.ListSubscriptions(ctx, &entity.ListSubscriptionsRequest{
+				Statuses: tt.statuses, AsOf: ptr("2026-06-10"), Limit: tt.limit, Offset: tt.offset,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, sub := range list {
+				got = append(got, sub.ServiceName)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -3,104 +3,476 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
+	"unicode/utf8"
 
+	"github.com/ShekleinAleksey/subscriptions/config"
 	"github.com/ShekleinAleksey/subscriptions/internal/entity"
 	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/ShekleinAleksey/subscriptions/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, req *entity.CreateSubscriptionRequest) (*entity.Subscription, error)
-	GetSubscription(ctx context.Context, id uuid.UUID) (*entity.Subscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID, asOf *string) (*entity.Subscription, error)
 	UpdateSubscription(ctx context.Context, id uuid.UUID, req *entity.UpdateSubscriptionRequest) error
 	DeleteSubscription(ctx context.Context, id uuid.UUID) error
-	ListSubscriptions(ctx context.Context, limit, offset int) ([]*entity.Subscription, error)
+	ListSubscriptions(ctx context.Context, req *entity.ListSubscriptionsRequest) ([]*entity.Subscription, error)
 	GetSubscriptionSummary(ctx context.Context, req *entity.SubscriptionSummaryRequest) (*entity.SubscriptionSummary, error)
+	GetSpendByTag(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.TagSpend, error)
+	GetActiveStats(ctx context.Context) ([]*entity.ServiceStats, error)
+	AddTags(ctx context.Context, id uuid.UUID, tags []string) (*entity.Subscription, error)
+	RemoveTag(ctx context.Context, id uuid.UUID, tag string) error
+	ListTags(ctx context.Context) ([]*entity.TagUsage, error)
+	GetSettlement(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.Debt, error)
+	GetForecast(ctx context.Context, req *entity.ForecastRequest) (*entity.Forecast, error)
+	ListDuplicates(ctx context.Context, userID *uuid.UUID) ([]*entity.DuplicateGroup, error)
+	MergeSubscriptions(ctx context.Context, id uuid.UUID, req *entity.MergeRequest) (*entity.Subscription, error)
+	CancelSubscription(ctx context.Context, id uuid.UUID, req *entity.CancelRequest) (*entity.Subscription, error)
+	PauseSubscription(ctx context.Context, id uuid.UUID, req *entity.LifecycleRequest) (*entity.Subscription, error)
+	ResumeSubscription(ctx context.Context, id uuid.UUID, req *entity.LifecycleRequest) (*entity.Subscription, error)
+	GetCancellationReport(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.CancellationStats, error)
+	SearchSubscriptions(ctx context.Context, req *entity.SearchRequest) ([]*entity.SearchResult, error)
+	SchedulePrice(ctx context.Context, id uuid.UUID, req *entity.SchedulePriceRequest) (*entity.Subscription, error)
+	CancelPriceChange(ctx context.Context, id uuid.UUID, effectiveFrom string) error
+	AddDiscount(ctx context.Context, id uuid.UUID, req *entity.DiscountRequest) (*entity.Subscription, error)
+	RemoveDiscount(ctx context.Context, id, discountID uuid.UUID) error
 }
 
 type subscriptionService struct {
-	repo repository.SubscriptionRepository
+	repo    repository.SubscriptionRepository
+	catalog repository.CatalogRepository
+	users   repository.UserRepository
+	budgets repository.BudgetRepository
+	events  repository.EventRepository
+	cfg     config.Subscriptions
 }
 
-func NewSubscriptionService(repo repository.SubscriptionRepository) SubscriptionService {
-	return &subscriptionService{repo: repo}
+func NewSubscriptionService(repo repository.SubscriptionRepository, catalog repository.CatalogRepository, users repository.UserRepository, budgets repository.BudgetRepository, events repository.EventRepository, cfg config.Subscriptions) SubscriptionService {
+	return &subscriptionService{repo: repo, catalog: catalog, users: users, budgets: budgets, events: events, cfg: cfg}
 }
 
 func (s *subscriptionService) CreateSubscription(ctx context.Context, req *entity.CreateSubscriptionRequest) (*entity.Subscription, error) {
-	startDate, err := time.Parse("01-2006", req.StartDate)
+	ctx, span := tracer.Start(ctx, "SubscriptionService.CreateSubscription")
+	defer span.End()
+
+	startDate, err := entity.ParseDate(req.StartDate)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start_date format: %w", err)
 	}
 
 	var endDate *time.Time
 	if req.EndDate != nil {
-		parsedEndDate, err := time.Parse("01-2006", *req.EndDate)
+		parsedEndDate, err := entity.ParseEndDate(*req.EndDate)
 		if err != nil {
 			return nil, fmt.Errorf("invalid end_date format: %w", err)
 		}
 		endDate = &parsedEndDate
 	}
 
+	trialEndDate, err := parseTrial(startDate, req.TrialEndDate, req.TrialMonths)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пользователь мог быть создан только что, поэтому читаем из основной базы
+	if _, err := s.users.GetByID(repository.WithPrimary(ctx), req.UserID); err != nil {
+		return nil, err
+	}
+
+	service, err := resolveService(ctx, s.catalog, req.ServiceID, req.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	price := req.Price
+	if price == 0 {
+		if service.DefaultPrice == nil {
+			return nil, fmt.Errorf("price is required: service %q has no default price", service.Name)
+		}
+		price = *service.DefaultPrice
+	}
+
+	participants, err := s.normalizeParticipants(ctx, req.UserID, []int{price}, req.Participants)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts := make([]entity.Discount, 0, len(req.Discounts))
+	for i := range req.Discounts {
+		discount, err := parseDiscount(startDate, &req.Discounts[i])
+		if err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, discount)
+	}
+
 	subscription := &entity.Subscription{
-		ID:          uuid.New(),
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      req.UserID,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		ID:           uuid.New(),
+		ServiceID:    service.ID,
+		ServiceName:  service.Name,
+		Price:        price,
+		UserID:       req.UserID,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		TrialEndDate: trialEndDate,
+		BillingDay:   req.BillingDay,
+		Tags:         tags,
+		Prices:       []entity.PricePeriod{{EffectiveFrom: entity.MonthStart(startDate), Price: price}},
+		Discounts:    discounts,
+		Participants: participants,
+	}
+	subscription.Evaluate(time.Now())
+
+	if subscription.Warnings, err = s.checkOverlaps(ctx, subscription); err != nil {
+		return nil, err
 	}
+	subscription.Exclusive = s.exclusive()
 
 	if err := s.repo.Create(ctx, subscription); err != nil {
 		return nil, err
 	}
+	s.checkBudgets(ctx, subscription)
 
 	return subscription, nil
 }
 
-func (s *subscriptionService) GetSubscription(ctx context.Context, id uuid.UUID) (*entity.Subscription, error) {
-	return s.repo.GetByID(ctx, id)
+// GetSubscription возвращает подписку с полями, вычисленными на день asOf (по умолчанию сейчас)
+func (s *subscriptionService) GetSubscription(ctx context.Context, id uuid.UUID, asOf *string) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetSubscription")
+	defer span.End()
+
+	at, err := parseAsOf(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		subscription.Evaluate(at)
+	}
+
+	return subscription, nil
 }
 
 func (s *subscriptionService) UpdateSubscription(ctx context.Context, id uuid.UUID, req *entity.UpdateSubscriptionRequest) error {
-	return s.repo.Update(ctx, id, req)
+	ctx, span := tracer.Start(ctx, "SubscriptionService.UpdateSubscription")
+	defer span.End()
+
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			return err
+		}
+		req.Tags = &tags
+	}
+
+	if req.ServiceID != nil || req.ServiceName != nil {
+		var name string
+		if req.ServiceName != nil {
+			name = *req.ServiceName
+		}
+		service, err := resolveService(ctx, s.catalog, req.ServiceID, name)
+		if err != nil {
+			return err
+		}
+		req.ServiceID, req.ServiceName = &service.ID, &service.Name
+	}
+
+	if req.TrialEndDate != nil || req.TrialMonths != nil {
+		if err := s.prepareTrial(ctx, id, req); err != nil {
+			return err
+		}
+	}
+
+	if req.ServiceID != nil || req.StartDate != nil || req.EndDate != nil {
+		if err := s.checkUpdateOverlaps(ctx, id, req); err != nil {
+			return err
+		}
+		exclusive := s.exclusive()
+		req.Exclusive = &exclusive
+	}
+
+	// Новая цена или новые участники проверяются вместе с тем, что уже сохранено
+	if req.Price != nil || req.Participants != nil {
+		current, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+		if err != nil {
+			return err
+		}
+		prices := futurePrices(current, time.Now())
+		if req.Price != nil {
+			// Прошлые месяцы сохраняют свою цену; у еще не начавшейся подписки меняется начальная цена
+			req.PriceFrom = entity.MonthStart(time.Now())
+			if start := entity.MonthStart(current.StartDate); start.After(req.PriceFrom) {
+				req.PriceFrom = start
+			}
+			prices = append(prices, *req.Price)
+		}
+		participants := current.Participants
+		if req.Participants != nil {
+			participants = *req.Participants
+		}
+		participants, err = s.normalizeParticipants(ctx, current.UserID, prices, participants)
+		if err != nil {
+			return err
+		}
+		if req.Participants != nil {
+			req.Participants = &participants
+		}
+	}
+
+	if err := s.repo.Update(ctx, id, req); err != nil {
+		return err
+	}
+
+	if updated, err := s.repo.GetByID(repository.WithPrimary(ctx), id); err == nil {
+		s.checkBudgets(ctx, updated)
+	}
+	return nil
 }
 
 func (s *subscriptionService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.DeleteSubscription")
+	defer span.End()
+
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *subscriptionService) ListSubscriptions(ctx context.Context, limit, offset int) ([]*entity.Subscription, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 50
+func (s *subscriptionService) ListSubscriptions(ctx context.Context, req *entity.ListSubscriptionsRequest) ([]*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ListSubscriptions")
+	defer span.End()
+
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 50
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
 	}
-	if offset < 0 {
-		offset = 0
+
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
 	}
+	req.Tags = tags
 
-	return s.repo.List(ctx, limit, offset)
+	asOf, err := parseAsOf(req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateStatuses(req.Statuses); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []*entity.Subscription
+	if len(req.Statuses) == 0 {
+		subscriptions, err = s.repo.List(ctx, req)
+	} else {
+		subscriptions, err = s.listByStatus(ctx, req, asOf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AsOf != nil {
+		for _, sub := range subscriptions {
+			sub.Evaluate(asOf)
+		}
+	}
+	return subscriptions, nil
+}
+
+// listByStatus отбирает страницу подписок с нужными статусами. Статус вычисляется, а не хранится:
+// хранилище отбирает подписки по датам (Subscription.MayHaveStatus) в порядке даты начала,
+// а паузы и старшинство статусов проверяются здесь. Если все статусы определяются датами,
+// страница берется из хранилища как есть, иначе пачки читаются, пока страница не заполнится.
+func (s *subscriptionService) listByStatus(ctx context.Context, req *entity.ListSubscriptionsRequest, asOf time.Time) ([]*entity.Subscription, error) {
+	filter := *req
+	filter.StatusDate = entity.DateOf(asOf)
+	if !slices.ContainsFunc(req.Statuses, func(status string) bool { return !entity.StatusByDates(status) }) {
+		return s.repo.List(ctx, &filter)
+	}
+
+	var page []*entity.Subscription
+	skip := req.Offset
+	filter.Limit = max(req.Limit, statusBatchSize)
+	for filter.Offset = 0; ; filter.Offset += filter.Limit {
+		batch, err := s.repo.List(ctx, &filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range filterByStatus(batch, req.Statuses, asOf) {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if page = append(page, sub); len(page) == req.Limit {
+				return page, nil
+			}
+		}
+		if len(batch) < filter.Limit {
+			return page, nil
+		}
+	}
 }
 
 func (s *subscriptionService) GetSubscriptionSummary(ctx context.Context, req *entity.SubscriptionSummaryRequest) (*entity.SubscriptionSummary, error) {
-	logrus.WithFields(logrus.Fields{
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetSubscriptionSummary")
+	defer span.End()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"user_id":      req.UserID,
 		"service_name": req.ServiceName,
 		"start_period": req.StartPeriod,
 		"end_period":   req.EndPeriod,
+		"tags":         req.Tags,
 	}).Debug("Calculating subscription summary")
 
+	if err := s.prepareSummary(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if req.UserID == nil {
+		return s.repo.Summary(ctx, req)
+	}
+
+	// Долю пользователя в совместных подписках хранилище не считает, поэтому читаются только его подписки
+	subscriptions, err := s.repo.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBillingWindow(req).summary(subscriptions, req.UserID), nil
+}
+
+// GetSpendByTag разбивает стоимость из GetSubscriptionSummary по тегам
+func (s *subscriptionService) GetSpendByTag(ctx context.Context, req *entity.SubscriptionSummaryRequest) ([]*entity.TagSpend, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetSpendByTag")
+	defer span.End()
+
+	if err := s.prepareSummary(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if req.UserID == nil {
+		return s.repo.SpendByTag(ctx, req)
+	}
+
+	subscriptions, err := s.repo.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBillingWindow(req).spendByTag(subscriptions, req.UserID), nil
+}
+
+// prepareSummary проверяет и нормализует фильтры summary
+func (s *subscriptionService) prepareSummary(ctx context.Context, req *entity.SubscriptionSummaryRequest) error {
 	// Валидация периодов если они переданы
 	if req.StartPeriod != nil {
 		if _, err := time.Parse("01-2006", *req.StartPeriod); err != nil {
-			return nil, fmt.Errorf("invalid start_period format: %w", err)
+			return fmt.Errorf("invalid start_period format: %w", err)
 		}
 	}
 	if req.EndPeriod != nil {
 		if _, err := time.Parse("01-2006", *req.EndPeriod); err != nil {
-			return nil, fmt.Errorf("invalid end_period format: %w", err)
+			return fmt.Errorf("invalid end_period format: %w", err)
+		}
+	}
+	if _, err := parseAsOf(req.AsOf); err != nil {
+		return err
+	}
+	if err := validateStatuses(req.Statuses); err != nil {
+		return err
+	}
+
+	// Название или алиас приводим к каноническому имени из каталога
+	if req.ServiceName != nil {
+		service, err := s.catalog.FindByName(ctx, *req.ServiceName)
+		if err != nil && err.Error() != "service not found" {
+			return err
 		}
+		if service != nil {
+			req.ServiceName = &service.Name
+		}
+	}
+
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return err
 	}
+	req.Tags = tags
+
+	return nil
+}
+
+// GetActiveStats возвращает показатели по подпискам, активным в текущем месяце
+func (s *subscriptionService) GetActiveStats(ctx context.Context) ([]*entity.ServiceStats, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetActiveStats")
+	defer span.End()
 
-	return s.repo.GetSummary(ctx, req)
+	return s.repo.ActiveStats(ctx, entity.MonthStart(time.Now()))
+}
+
+// AddTags добавляет теги к подписке и возвращает ее с полным списком тегов
+func (s *subscriptionService) AddTags(ctx context.Context, id uuid.UUID, tags []string) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.AddTags")
+	defer span.End()
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddTags(ctx, id, tags); err != nil {
+		return nil, err
+	}
+
+	// Подписка могла попасть под бюджет на новый тег
+	updated, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	s.checkBudgets(ctx, updated)
+	return updated, nil
+}
+
+func (s *subscriptionService) RemoveTag(ctx context.Context, id uuid.UUID, tag string) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.RemoveTag")
+	defer span.End()
+
+	return s.repo.RemoveTag(ctx, id, entity.NormalizeTag(tag))
+}
+
+func (s *subscriptionService) ListTags(ctx context.Context) ([]*entity.TagUsage, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ListTags")
+	defer span.End()
+
+	return s.repo.ListTags(ctx)
+}
+
+// normalizeTags приводит теги к единому виду, убирает повторы и сортирует их, как при чтении из базы
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = entity.NormalizeTag(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > entity.MaxTagLength {
+			return nil, fmt.Errorf("invalid tag %q: must be 1 to %d characters", tag, entity.MaxTagLength)
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/google/uuid"
+)
+
+// parseTrial возвращает последний бесплатный месяц по trial_end_date (месяц MM-YYYY или дата
+// YYYY-MM-DD в нем) или по trial_months от начала подписки start; nil — пробного периода нет
+func parseTrial(start time.Time, trialEndDate *string, trialMonths *int) (*time.Time, error) {
+	var trialEnd time.Time
+	switch {
+	case trialMonths != nil:
+		if *trialMonths < 1 {
+			return nil, fmt.Errorf("invalid trial_months: must be positive")
+		}
+		trialEnd = entity.TrialEnd(start, *trialMonths)
+	case trialEndDate != nil && *trialEndDate != "":
+		parsed, err := entity.ParseDate(*trialEndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trial_end_date format: %w", err)
+		}
+		// Пробный период считается месяцами, как и TrialEnd
+		trialEnd = entity.MonthStart(parsed)
+	default:
+		return nil, nil
+	}
+
+	if trialEnd.Before(entity.MonthStart(start)) {
+		return nil, fmt.Errorf("invalid trial_end_date: must not be before start_date")
+	}
+	return &trialEnd, nil
+}
+
+// prepareTrial переводит trial_end_date или trial_months в месяц TrialEnd от новой или сохраненной даты начала
+func (s *subscriptionService) prepareTrial(ctx context.Context, id uuid.UUID, req *entity.UpdateSubscriptionRequest) error {
+	var start time.Time
+	if req.StartDate != nil {
+		parsed, err := entity.ParseDate(*req.StartDate)
+		if err != nil {
+			return fmt.Errorf("invalid start_date format: %w", err)
+		}
+		start = parsed
+	} else {
+		current, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+		if err != nil {
+			return err
+		}
+		start = current.StartDate
+	}
+
+	trialEnd, err := parseTrial(start, req.TrialEndDate, req.TrialMonths)
+	if err != nil {
+		return err
+	}
+
+	// Пустая строка и TrialEnd = nil убирают пробный период
+	trialEndDate := ""
+	if trialEnd != nil {
+		trialEndDate = trialEnd.Format(entity.MonthLayout)
+	}
+	req.TrialEndDate, req.TrialEnd, req.TrialMonths = &trialEndDate, trialEnd, nil
+	return nil
+}
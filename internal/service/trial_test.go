@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShekleinAleksey/subscriptions/config"
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+)
+
+func TestParseTrial(t *testing.T) {
+	tests := []struct {
+		name         string
+		start        string
+		trialEndDate *string
+		trialMonths  *int
+		want         string
+		wantErr      bool
+	}{
+		{name: "без пробного периода", start: "2026-01-20"},
+		{name: "пустой trial_end_date", start: "2026-01-20", trialEndDate: ptr("")},
+		{name: "месяц", start: "2026-01-20", trialEndDate: ptr("03-2026"), want: "2026-03-01"},
+		{name: "дата внутри месяца", start: "2026-01-20", trialEndDate: ptr("2026-03-15"), want: "2026-03-01"},
+		{name: "месяц начала", start: "2026-01-20", trialEndDate: ptr("2026-01-05"), want: "2026-01-01"},
+		{name: "число месяцев", start: "2026-01-20", trialMonths: ptr(2), want: "2026-02-01"},
+		{name: "раньше начала", start: "2026-01-20", trialEndDate: ptr("2025-12-31"), wantErr: true},
+		{name: "неверный формат", start: "2026-01-20", trialEndDate: ptr("15.03.2026"), wantErr: true},
+		{name: "ноль месяцев", start: "2026-01-20", trialMonths: ptr(0), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTrial(day(tt.start), tt.trialEndDate, tt.trialMonths)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("trial end = %s, want none", got.Format(entity.DateLayout))
+			case tt.want != "" && (got == nil || !got.Equal(day(tt.want))):
+				t.Errorf("trial end = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateSubscriptionTrial(t *testing.T) {
+	ctx := context.Background()
+	svc, userID := newTestService(t, config.OverlapWarn)
+	subscriptions := svc.SubscriptionService
+
+	created, err := subscriptions.CreateSubscription(ctx, &entity.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 100, UserID: userID, StartDate: "2026-01-20", TrialEndDate: ptr("2026-02-10"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.TrialEndDate == nil || !created.TrialEndDate.Equal(day("2026-02-01")) {
+		t.Errorf("created trial end = %v, want 2026-02-01", created.TrialEndDate)
+	}
+
+	tests := []struct {
+		name string
+		req  entity.UpdateSubscriptionRequest
+		want string
+	}{
+		{name: "дата внутри месяца", req: entity.UpdateSubscriptionRequest{TrialEndDate: ptr("2026-04-20")}, want: "2026-04-01"},
+		{name: "число месяцев", req: entity.UpdateSubscriptionRequest{TrialMonths: ptr(3)}, want: "2026-03-01"},
+		{name: "от новой даты начала", req: entity.UpdateSubscriptionRequest{StartDate: ptr("2026-02-01"), TrialMonths: ptr(1)}, want: "2026-02-01"},
+		{name: "пустая строка убирает", req: entity.UpdateSubscriptionRequest{TrialEndDate: ptr("")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := subscriptions.UpdateSubscription(ctx, created.ID, &tt.req); err != nil {
+				t.Fatal(err)
+			}
+			got, err := subscriptions.GetSubscription(ctx, created.ID, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.want == "" && got.TrialEndDate != nil:
+				t.Errorf("trial end = %s, want none", got.TrialEndDate.Format(entity.DateLayout))
+			case tt.want != "" && (got.TrialEndDate == nil || !got.TrialEndDate.Equal(day(tt.want))):
+				t.Errorf("trial end = %v, want %s", got.TrialEndDate, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ShekleinAleksey/subscriptions/internal/entity"
+	"github.com/ShekleinAleksey/subscriptions/internal/repository"
+	"github.com/google/uuid"
+)
+
+type UserService interface {
+	CreateUser(ctx context.Context, req *entity.CreateUserRequest) (*entity.User, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*entity.User, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, req *entity.UpdateUserRequest) (*entity.User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	ListUsers(ctx context.Context, limit, offset int) ([]*entity.User, error)
+}
+
+type userService struct {
+	repo repository.UserRepository
+}
+
+func NewUserService(repo repository.UserRepository) UserService {
+	return &userService{repo: repo}
+}
+
+func (s *userService) CreateUser(ctx context.Context, req *entity.CreateUserRequest) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	user := &entity.User{
+		ID:          uuid.New(),
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Email:       req.Email,
+		Currency:    req.Currency,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	if req.ID != nil {
+		user.ID = *req.ID
+	}
+	if user.Currency == "" {
+		user.Currency = entity.DefaultCurrency
+	}
+	prepareUser(user)
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) GetUser(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUser")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *userService) UpdateUser(ctx context.Context, id uuid.UUID, req *entity.UpdateUserRequest) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
+	user, err := s.repo.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Email != nil {
+		user.Email = req.Email
+	}
+	if req.Currency != nil {
+		user.Currency = *req.Currency
+	}
+	prepareUser(user)
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *userService) ListUsers(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUsers")
+	defer span.End()
+
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.List(ctx, limit, offset)
+}
+
+// prepareUser приводит email к нижнему регистру; пустой email означает, что адреса нет
+func prepareUser(user *entity.User) {
+	if user.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*user.Email))
+		if email == "" {
+			user.Email = nil
+		} else {
+			user.Email = &email
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Package worker запускает периодические фоновые задачи сервера
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job — периодическая задача; ошибка логируется, следующий запуск идет по расписанию
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Run выполняет jobs сразу и затем каждые interval, пока не отменен ctx
+func Run(ctx context.Context, interval time.Duration, jobs ...Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, job := range jobs {
+			if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).WithField("job", job.Name).Error("Background job failed")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
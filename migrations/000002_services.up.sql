@@ -0,0 +1,38 @@
+CREATE TABLE services (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    -- name_key и alias_key — нормализованные названия (нижний регистр, без лишних пробелов)
+    name_key VARCHAR(255) NOT NULL UNIQUE,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    website VARCHAR(255) NOT NULL DEFAULT '',
+    default_price INTEGER NULL CHECK (default_price > 0)
+);
+
+CREATE TABLE service_aliases (
+    alias_key VARCHAR(255) PRIMARY KEY,
+    alias VARCHAR(255) NOT NULL,
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_service_aliases_service_id ON service_aliases(service_id);
+
+-- Каталог из существующих названий: одна запись на нормализованное имя
+INSERT INTO services (name, name_key)
+SELECT DISTINCT ON (name_key) name, name_key
+FROM (
+    SELECT regexp_replace(trim(service_name), '\s+', ' ', 'g') AS name,
+           lower(regexp_replace(trim(service_name), '\s+', ' ', 'g')) AS name_key
+    FROM subscriptions
+) names
+ORDER BY name_key, name;
+
+ALTER TABLE subscriptions ADD COLUMN service_id UUID REFERENCES services(id);
+
+UPDATE subscriptions s
+SET service_id = c.id, service_name = c.name
+FROM services c
+WHERE c.name_key = lower(regexp_replace(trim(s.service_name), '\s+', ' ', 'g'));
+
+ALTER TABLE subscriptions ALTER COLUMN service_id SET NOT NULL;
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(service_id);
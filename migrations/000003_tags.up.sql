@@ -0,0 +1,7 @@
+CREATE TABLE subscription_tags (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (subscription_id, tag)
+);
+
+CREATE INDEX idx_subscription_tags_tag ON subscription_tags(tag);
@@ -0,0 +1,14 @@
+CREATE TABLE users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    email VARCHAR(255) NULL UNIQUE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Пользователи, на которых уже ссылаются подписки, с профилем по умолчанию
+INSERT INTO users (id) SELECT DISTINCT user_id FROM subscriptions;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT fk_subscriptions_user FOREIGN KEY (user_id) REFERENCES users(id);
@@ -0,0 +1,11 @@
+CREATE TABLE subscription_participants (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    weight INTEGER NULL CHECK (weight > 0),
+    amount INTEGER NULL CHECK (amount >= 0),
+    PRIMARY KEY (subscription_id, user_id),
+    -- Участник задает либо вес, либо фиксированную сумму
+    CHECK ((weight IS NULL) <> (amount IS NULL))
+);
+
+CREATE INDEX idx_subscription_participants_user_id ON subscription_participants(user_id);
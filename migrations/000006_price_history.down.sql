@@ -0,0 +1,14 @@
+ALTER TABLE subscriptions ADD COLUMN price INTEGER NULL CHECK (price > 0);
+
+-- Возвращается последняя цена, включая запланированные изменения
+UPDATE subscriptions s
+SET price = (
+    SELECT p.price FROM subscription_prices p
+    WHERE p.subscription_id = s.id
+    ORDER BY p.effective_from DESC
+    LIMIT 1
+);
+
+ALTER TABLE subscriptions ALTER COLUMN price SET NOT NULL;
+
+DROP TABLE IF EXISTS subscription_prices;
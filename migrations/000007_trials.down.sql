@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS events;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS trial_end_date;
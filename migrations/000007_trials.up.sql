@@ -0,0 +1,16 @@
+-- Последний бесплатный месяц пробного периода включительно
+ALTER TABLE subscriptions ADD COLUMN trial_end_date DATE NULL;
+
+-- События для пользователя, например напоминание о конце пробного периода
+CREATE TABLE events (
+    id UUID PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subscription_id UUID NULL REFERENCES subscriptions(id) ON DELETE SET NULL,
+    message TEXT NOT NULL,
+    -- Ключ не дает фоновой задаче создать одно и то же событие повторно
+    dedup_key VARCHAR(255) NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_events_user_id ON events(user_id, created_at);
@@ -0,0 +1,13 @@
+CREATE TABLE budgets (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period VARCHAR(16) NOT NULL CHECK (period IN ('monthly', 'yearly')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    -- Бюджет на сервис или на тег (категорию); без обоих — на все подписки пользователя
+    service_id UUID NULL REFERENCES services(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (service_id IS NULL OR tag IS NULL)
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets(user_id);
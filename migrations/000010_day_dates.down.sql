@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS billing_day;
+
+UPDATE subscriptions
+SET start_date = date_trunc('month', start_date)::date,
+    end_date = date_trunc('month', end_date)::date;
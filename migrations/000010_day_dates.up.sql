@@ -0,0 +1,8 @@
+-- До появления дат с точностью до дня end_date хранил первое число последнего оплаченного месяца;
+-- теперь это последний день подписки, поэтому переносим его на конец месяца
+UPDATE subscriptions
+SET end_date = (date_trunc('month', end_date) + INTERVAL '1 month - 1 day')::date
+WHERE end_date IS NOT NULL;
+
+-- День месяца, в который списывается оплата; NULL — день начала подписки
+ALTER TABLE subscriptions ADD COLUMN billing_day SMALLINT NULL CHECK (billing_day BETWEEN 1 AND 31);
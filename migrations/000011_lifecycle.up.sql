@@ -0,0 +1,16 @@
+-- Причина отмены; дата отмены — end_date подписки
+ALTER TABLE subscriptions ADD COLUMN cancel_reason VARCHAR(32) NULL;
+ALTER TABLE subscriptions ADD COLUMN cancel_comment TEXT NULL;
+ALTER TABLE subscriptions ADD COLUMN cancelled_at TIMESTAMPTZ NULL;
+
+CREATE TABLE subscription_pauses (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    -- Первый и последний день паузы включительно; без end_date подписка еще на паузе
+    start_date DATE NOT NULL,
+    end_date DATE NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_subscription_pauses_subscription_id ON subscription_pauses(subscription_id);
@@ -0,0 +1,4 @@
+-- Поиск по названию сервиса с опечатками: оператор % и ILIKE '%...%' используют GIN-индекс по триграммам
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_subscriptions_service_name_trgm ON subscriptions USING gin (service_name gin_trgm_ops);
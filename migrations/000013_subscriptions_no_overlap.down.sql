@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_no_overlap;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS exclusive;
+
+DROP EXTENSION IF EXISTS btree_gist;
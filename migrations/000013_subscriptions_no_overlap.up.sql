@@ -0,0 +1,12 @@
+-- Пересечения при политике reject проверяет сервис, но два параллельных запроса могут пройти проверку оба.
+-- Ограничение запрещает пересечение периодов подписок пользователя на один сервис, сохраненных при reject;
+-- подписки, сохраненные при warn (exclusive = false), и уже накопленные дубликаты его не нарушают.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE subscriptions ADD COLUMN exclusive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_no_overlap EXCLUDE USING gist (
+    user_id WITH =,
+    service_id WITH =,
+    daterange(start_date, COALESCE(end_date, 'infinity'), '[]') WITH &&
+) WHERE (exclusive);
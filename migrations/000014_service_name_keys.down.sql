@@ -0,0 +1,2 @@
+-- Слитые сервисы и прежние ключи не восстановить, да и не нужно: новые ключи тоже нормализованы
+SELECT 1;
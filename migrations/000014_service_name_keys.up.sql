@@ -0,0 +1,3 @@
+-- В Postgres 000002 уже строила ключи как entity.NormalizeServiceName: lower() знает Юникод,
+-- а regexp_replace схлопывает пробелы. Миграция исправляет только SQLite и держит версии схем вровень.
+SELECT 1;
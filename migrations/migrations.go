@@ -0,0 +1,40 @@
+// Package migrations встраивает SQL-миграции в бинарник,
+// чтобы применять их без внешнего migrate CLI и каталога рядом с бинарником.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// SQLiteFS — те же миграции в диалекте SQLite. Номера версий совпадают с FS,
+// поэтому LatestVersion одинакова для обоих хранилищ.
+//
+//go:embed sqlite/*.sql
+var SQLiteFS embed.FS
+
+// LatestVersion возвращает номер последней встроенной миграции —
+// версию схемы, которую ожидает текущий код
+func LatestVersion() uint {
+	entries, _ := fs.ReadDir(FS, ".")
+
+	var latest uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(version))
+	}
+
+	return latest
+}
@@ -0,0 +1,100 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/sirupsen/logrus"
+)
+
+// Migrator применяет встроенные миграции через драйвер конкретной БД
+// (см. postgres.NewMigrator и sqlite.NewMigrator)
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+type Status struct {
+	Version uint
+	Dirty   bool
+	Latest  uint
+	Applied bool
+}
+
+// NewMigrator создает мигратор для миграций из каталога dir файловой системы fsys.
+// При ошибке driver закрывается.
+func NewMigrator(fsys fs.FS, dir, dbName string, driver database.Driver) (*Migrator, error) {
+	source, err := iofs.New(fsys, dir)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, dbName, driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	m.Log = migrateLogger{}
+
+	return &Migrator{m: m}, nil
+}
+
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+// Down откатывает steps последних миграций
+func (m *Migrator) Down(steps int) error {
+	if err := m.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+	return nil
+}
+
+// Force выставляет версию схемы без выполнения миграций, снимая флаг dirty
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force migration version: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) Status() (*Status, error) {
+	status := &Status{Latest: LatestVersion(), Applied: true}
+
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		status.Applied = false
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	status.Version = version
+	status.Dirty = dirty
+	return status, nil
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	logrus.Info("migrate: " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
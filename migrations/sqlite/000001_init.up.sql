@@ -0,0 +1,14 @@
+CREATE TABLE subscriptions (
+    id TEXT PRIMARY KEY,
+    service_name VARCHAR(255) NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    user_id TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NULL
+);
+
+CREATE INDEX idx_subscriptions_user_id ON subscriptions(user_id);
+CREATE INDEX idx_subscriptions_service_name ON subscriptions(service_name);
+CREATE INDEX idx_subscriptions_start_date ON subscriptions(start_date);
+CREATE INDEX idx_subscriptions_end_date ON subscriptions(end_date);
+CREATE INDEX idx_subscriptions_date_range ON subscriptions(start_date, end_date);
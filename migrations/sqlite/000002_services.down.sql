@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_subscriptions_service_id;
+ALTER TABLE subscriptions DROP COLUMN service_id;
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
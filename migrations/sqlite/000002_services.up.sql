@@ -0,0 +1,42 @@
+CREATE TABLE services (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- name_key и alias_key — нормализованные названия (нижний регистр, без лишних пробелов)
+    name_key VARCHAR(255) NOT NULL UNIQUE,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    website VARCHAR(255) NOT NULL DEFAULT '',
+    default_price INTEGER NULL CHECK (default_price > 0)
+);
+
+CREATE TABLE service_aliases (
+    alias_key VARCHAR(255) PRIMARY KEY,
+    alias VARCHAR(255) NOT NULL,
+    service_id TEXT NOT NULL REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_service_aliases_service_id ON service_aliases(service_id);
+
+-- Каталог из существующих названий: одна запись на нормализованное имя.
+-- lower() в SQLite меняет регистр только латиницы; ключи по entity.NormalizeServiceName достраивает 000014.
+INSERT INTO services (id, name, name_key)
+SELECT lower(substr(h, 1, 8) || '-' || substr(h, 9, 4) || '-4' || substr(h, 14, 3) || '-8'
+             || substr(h, 18, 3) || '-' || substr(h, 21, 12)),
+       name, name_key
+FROM (
+    SELECT hex(randomblob(16)) AS h, name, name_key
+    FROM (
+        SELECT MIN(trim(service_name)) AS name, lower(trim(service_name)) AS name_key
+        FROM subscriptions
+        GROUP BY lower(trim(service_name))
+    )
+);
+
+-- Без REFERENCES: SQLite не умеет удалять столбец внешнего ключа в down-миграции,
+-- удаление используемого сервиса запрещает репозиторий
+ALTER TABLE subscriptions ADD COLUMN service_id TEXT NULL;
+
+UPDATE subscriptions
+SET service_id = (SELECT id FROM services WHERE name_key = lower(trim(subscriptions.service_name))),
+    service_name = (SELECT name FROM services WHERE name_key = lower(trim(subscriptions.service_name)));
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(service_id);
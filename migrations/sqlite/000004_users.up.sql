@@ -0,0 +1,12 @@
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    email VARCHAR(255) NULL UNIQUE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Пользователи, на которых уже ссылаются подписки, с профилем по умолчанию.
+-- Внешний ключ на users к существующей таблице SQLite не добавляет — ссылку проверяет сервис.
+INSERT INTO users (id) SELECT DISTINCT user_id FROM subscriptions;
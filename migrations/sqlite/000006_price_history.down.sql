@@ -0,0 +1,13 @@
+-- SQLite не умеет добавлять NOT NULL столбец без значения по умолчанию
+ALTER TABLE subscriptions ADD COLUMN price INTEGER NOT NULL DEFAULT 0;
+
+-- Возвращается последняя цена, включая запланированные изменения
+UPDATE subscriptions
+SET price = (
+    SELECT p.price FROM subscription_prices p
+    WHERE p.subscription_id = subscriptions.id
+    ORDER BY p.effective_from DESC
+    LIMIT 1
+);
+
+DROP TABLE IF EXISTS subscription_prices;
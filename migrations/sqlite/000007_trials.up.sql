@@ -0,0 +1,16 @@
+-- Последний бесплатный месяц пробного периода включительно
+ALTER TABLE subscriptions ADD COLUMN trial_end_date DATE NULL;
+
+-- События для пользователя, например напоминание о конце пробного периода
+CREATE TABLE events (
+    id TEXT PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subscription_id TEXT NULL REFERENCES subscriptions(id) ON DELETE SET NULL,
+    message TEXT NOT NULL,
+    -- Ключ не дает фоновой задаче создать одно и то же событие повторно
+    dedup_key VARCHAR(255) NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_events_user_id ON events(user_id, created_at);
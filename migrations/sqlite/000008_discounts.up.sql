@@ -0,0 +1,14 @@
+CREATE TABLE subscription_discounts (
+    id TEXT PRIMARY KEY,
+    subscription_id TEXT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    percent INTEGER NULL CHECK (percent BETWEEN 1 AND 100),
+    amount INTEGER NULL CHECK (amount > 0),
+    -- Первый и последний месяц скидки включительно; без valid_to скидка бессрочная
+    valid_from DATE NOT NULL,
+    valid_to DATE NULL,
+    -- Скидка задается либо процентом, либо фиксированной суммой
+    CHECK ((percent IS NULL) <> (amount IS NULL))
+);
+
+CREATE INDEX idx_subscription_discounts_subscription_id ON subscription_discounts(subscription_id);
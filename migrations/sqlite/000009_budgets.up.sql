@@ -0,0 +1,13 @@
+CREATE TABLE budgets (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period VARCHAR(16) NOT NULL CHECK (period IN ('monthly', 'yearly')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    -- Бюджет на сервис или на тег (категорию); без обоих — на все подписки пользователя
+    service_id TEXT NULL REFERENCES services(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (service_id IS NULL OR tag IS NULL)
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets(user_id);
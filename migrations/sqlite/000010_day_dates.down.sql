@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions DROP COLUMN billing_day;
+
+UPDATE subscriptions
+SET start_date = strftime('%Y-%m-%d 00:00:00+00:00', start_date, 'start of month'),
+    end_date = strftime('%Y-%m-%d 00:00:00+00:00', end_date, 'start of month');
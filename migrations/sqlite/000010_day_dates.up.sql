@@ -0,0 +1,8 @@
+-- До появления дат с точностью до дня end_date хранил первое число последнего оплаченного месяца;
+-- теперь это последний день подписки, поэтому переносим его на конец месяца
+UPDATE subscriptions
+SET end_date = strftime('%Y-%m-%d 00:00:00+00:00', end_date, 'start of month', '+1 month', '-1 day')
+WHERE end_date IS NOT NULL;
+
+-- День месяца, в который списывается оплата; NULL — день начала подписки
+ALTER TABLE subscriptions ADD COLUMN billing_day INTEGER NULL CHECK (billing_day BETWEEN 1 AND 31);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS subscription_pauses;
+
+ALTER TABLE subscriptions DROP COLUMN cancelled_at;
+ALTER TABLE subscriptions DROP COLUMN cancel_comment;
+ALTER TABLE subscriptions DROP COLUMN cancel_reason;
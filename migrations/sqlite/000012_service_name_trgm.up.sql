@@ -0,0 +1,3 @@
+-- В SQLite нет pg_trgm: поиск по названию ранжируется в приложении, индекс не нужен.
+-- Миграция оставлена, чтобы версии схемы совпадали с Postgres.
+SELECT 1;
@@ -0,0 +1,3 @@
+-- В SQLite нет ограничений исключения: пересечения проверяет только сервис, а записи и так идут
+-- по одной. Колонка нужна, чтобы запросы совпадали с Postgres.
+ALTER TABLE subscriptions ADD COLUMN exclusive BOOLEAN NOT NULL DEFAULT FALSE;
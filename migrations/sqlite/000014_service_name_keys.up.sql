@@ -0,0 +1,34 @@
+-- 000002 строила name_key через lower(trim()): кириллица оставалась в исходном регистре,
+-- а пробелы внутри названия не схлопывались, поэтому поиск по ключу entity.NormalizeServiceName
+-- не находил такие сервисы и каталог заводил дубликаты. service_name_key регистрирует pkg/sqlite.
+-- Сервисы с одинаковым ключом сливаются в один: остается тот, чей ключ уже верный, иначе с меньшим id.
+CREATE TABLE service_keepers AS
+SELECT s.id, (
+    SELECT k.id FROM services k
+    WHERE service_name_key(k.name) = service_name_key(s.name)
+    ORDER BY k.name_key = service_name_key(k.name) DESC, k.id
+    LIMIT 1
+) AS keeper_id
+FROM services s;
+
+DELETE FROM service_keepers WHERE id = keeper_id;
+
+UPDATE subscriptions
+SET service_id = (SELECT keeper_id FROM service_keepers WHERE id = subscriptions.service_id),
+    service_name = (SELECT s.name FROM service_keepers k JOIN services s ON s.id = k.keeper_id
+                    WHERE k.id = subscriptions.service_id)
+WHERE service_id IN (SELECT id FROM service_keepers);
+
+UPDATE service_aliases
+SET service_id = (SELECT keeper_id FROM service_keepers WHERE id = service_aliases.service_id)
+WHERE service_id IN (SELECT id FROM service_keepers);
+
+UPDATE budgets
+SET service_id = (SELECT keeper_id FROM service_keepers WHERE id = budgets.service_id)
+WHERE service_id IN (SELECT id FROM service_keepers);
+
+DELETE FROM services WHERE id IN (SELECT id FROM service_keepers);
+
+DROP TABLE service_keepers;
+
+UPDATE services SET name_key = service_name_key(name) WHERE name_key <> service_name_key(name);
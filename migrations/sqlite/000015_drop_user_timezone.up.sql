@@ -0,0 +1,3 @@
+-- Часовой пояс хранился и проверялся, но ни в одном расчете не участвовал:
+-- месяцы и даты подписок календарные, без привязки ко времени суток
+ALTER TABLE users DROP COLUMN timezone;
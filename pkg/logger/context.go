@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type entryKey struct{}
+
+// WithEntry кладет в контекст запись logrus с полями запроса (request_id и т.п.)
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// FromContext возвращает запись logrus, привязанную к запросу,
+// или стандартный логгер, если запрос ее не установил.
+// Контекст передается в запись, чтобы в лог попали trace_id и span_id.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+		return entry.WithContext(ctx)
+	}
+	return logrus.WithContext(ctx)
+}
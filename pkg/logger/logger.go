@@ -17,5 +17,5 @@ func SetLogrus(level string) {
 		TimestampFormat: "2006-01-02 15:04:05",
 	})
 	logrus.SetOutput(os.Stdout)
-
+	logrus.AddHook(traceHook{})
 }
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShekleinAleksey/subscriptions/migrations"
+	migratepg "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewMigrator создает мигратор, работающий через отдельное соединение из пула db.
+// Драйвер golang-migrate берет pg_advisory_lock на время каждой операции,
+// поэтому реплики, одновременно запустившие up, не мешают друг другу.
+func NewMigrator(ctx context.Context, db *sqlx.DB) (*migrations.Migrator, error) {
+	// Отдельное соединение, чтобы Close мигратора не закрыл общий пул
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for migrations: %w", err)
+	}
+
+	driver, err := migratepg.WithConnection(ctx, conn, &migratepg.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	return migrations.NewMigrator(migrations.FS, ".", "postgres", driver)
+}
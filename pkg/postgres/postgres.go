@@ -1,24 +1,100 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/ShekleinAleksey/subscriptions/config"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
 )
 
-func NewDB(cfg config.Config) (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-		cfg.DB.Host, cfg.DB.Port, cfg.DB.User, cfg.DB.DBName, cfg.DB.Password, cfg.DB.SSLMode))
+// maxConnectBackoff ограничивает рост паузы между попытками подключения
+const maxConnectBackoff = 30 * time.Second
+
+// NewDB открывает пул соединений с основной базой и дожидается ее доступности
+func NewDB(ctx context.Context, cfg config.Config) (*sqlx.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
+		cfg.DB.Host, cfg.DB.Port, cfg.DB.User, cfg.DB.DBName, cfg.DB.Password, cfg.DB.SSLMode)
+
+	return open(ctx, dsn, cfg.DB)
+}
+
+// NewReplicaDB открывает пул соединений с репликой для чтения.
+// Если реплика не настроена, возвращает nil без ошибки.
+func NewReplicaDB(ctx context.Context, cfg config.Config) (*sqlx.DB, error) {
+	if cfg.DB.ReplicaDSN == "" {
+		return nil, nil
+	}
+
+	return open(ctx, cfg.DB.ReplicaDSN, cfg.DB)
+}
+
+func open(ctx context.Context, dsn string, cfg config.DB) (*sqlx.DB, error) {
+	if cfg.StatementTimeout > 0 {
+		dsn = withStatementTimeout(dsn, cfg.StatementTimeout)
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Ping()
-	if err != nil {
+	db.SetMaxOpenConns(cfg.Pool.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Pool.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Pool.ConnMaxIdleTime)
+
+	if err := ping(ctx, db, cfg); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
+
+// ping ждет доступности базы, повторяя попытки с экспоненциальной паузой,
+// чтобы сервис не падал, если стартовал раньше Postgres
+func ping(ctx context.Context, db *sqlx.DB, cfg config.DB) error {
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.ConnectAttempts {
+			return fmt.Errorf("database is unavailable after %d attempts: %w", attempt, err)
+		}
+
+		logrus.WithError(err).Warnf("Database is unavailable, retrying in %s (attempt %d of %d)", backoff, attempt, cfg.ConnectAttempts)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database is unavailable: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// withStatementTimeout добавляет в DSN параметр statement_timeout, который lib/pq
+// передает серверу при установке соединения. Поддерживаются URL и key=value форматы.
+func withStatementTimeout(dsn string, timeout time.Duration) string {
+	ms := fmt.Sprint(timeout.Milliseconds())
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		q := u.Query()
+		q.Set("statement_timeout", ms)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	return dsn + " statement_timeout=" + ms
+}
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// service_name_key нужна миграциям каталога: lower() в SQLite меняет регистр только латиницы
+// и не схлопывает пробелы, а ключи должны совпадать с entity.NormalizeServiceName,
+// по которым репозиторий ищет сервисы
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("service_name_key", 1, serviceNameKey)
+}
+
+func serviceNameKey(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	var name string
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		name = v
+	case []byte:
+		name = string(v)
+	default:
+		return nil, nil
+	}
+	return strings.ToLower(strings.Join(strings.Fields(name), " ")), nil
+}
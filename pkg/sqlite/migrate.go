@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ShekleinAleksey/subscriptions/migrations"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+)
+
+// NewMigrator создает мигратор для базы в файле path. Драйвер golang-migrate
+// закрывает свое соединение в Close, поэтому открываем отдельное, а не берем общий пул.
+func NewMigrator(path string) (*migrations.Migrator, error) {
+	db, err := sql.Open("sqlite", dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+
+	driver, err := migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	return migrations.NewMigrator(migrations.SQLiteFS, "sqlite", "sqlite", driver)
+}
@@ -0,0 +1,43 @@
+// Package sqlite открывает файловую базу SQLite через pure-Go драйвер modernc.org/sqlite,
+// чтобы сервис работал одним бинарником без Postgres.
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+// NewDB открывает базу в файле path, создавая его при необходимости
+func NewDB(ctx context.Context, path string) (*sqlx.DB, error) {
+	db, err := sqlx.Open("sqlite", dsn(path))
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite допускает только одного писателя; одно соединение избавляет от SQLITE_BUSY
+	// внутри процесса, а запросы к небольшой локальной базе быстры и так
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// dsn включает внешние ключи, WAL и ожидание блокировки, а даты хранит в формате SQLite,
+// понятном функциям date() и сравнимом как строки
+func dsn(path string) string {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Set("_time_format", "sqlite")
+
+	return "file:" + path + "?" + q.Encode()
+}
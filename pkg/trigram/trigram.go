@@ -0,0 +1,131 @@
+// Package trigram повторяет сравнение строк расширения pg_trgm для хранилищ без него
+// и подсвечивает совпавшие фрагменты.
+package trigram
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Threshold — порог похожести оператора % в pg_trgm по умолчанию (pg_trgm.similarity_threshold)
+const Threshold = 0.3
+
+// word — слово строки: буквы и цифры в нижнем регистре и позиция первого символа в исходных рунах
+type word struct {
+	runes []rune
+	start int
+}
+
+// words разбивает s на слова так же, как pg_trgm: по символам, не являющимся буквой или цифрой
+func words(s []rune) []word {
+	var result []word
+	var current []rune
+	start := 0
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if current == nil {
+				start = i
+			}
+			current = append(current, unicode.ToLower(r))
+			continue
+		}
+		if current != nil {
+			result = append(result, word{runes: current, start: start})
+			current = nil
+		}
+	}
+	if current != nil {
+		result = append(result, word{runes: current, start: start})
+	}
+	return result
+}
+
+// padded дополняет слово как pg_trgm: два пробела в начале и один в конце
+func padded(w word) []rune {
+	return append(append([]rune{' ', ' '}, w.runes...), ' ')
+}
+
+// Set возвращает множество триграмм строки s
+func Set(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range words([]rune(s)) {
+		p := padded(w)
+		for i := 0; i+3 <= len(p); i++ {
+			set[string(p[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// Similarity возвращает похожесть строк от 0 до 1, как similarity() в pg_trgm:
+// доля общих триграмм среди всех триграмм обеих строк
+func Similarity(a, b string) float64 {
+	setA, setB := Set(a), Set(b)
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range setA {
+		if setB[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(setA)+len(setB)-common)
+}
+
+// Match сообщает, что s подходит под запрос query так же, как в SQL-запросе поиска:
+// s % query или s ILIKE '%query%'
+func Match(s, query string) bool {
+	return Similarity(s, query) >= Threshold || strings.Contains(strings.ToLower(s), strings.ToLower(query))
+}
+
+// Highlight возвращает s, экранированную для HTML, с фрагментами, совпавшими с query, в <mark>:
+// вхождение query целиком и символы, покрытые общими триграммами
+func Highlight(s, query string) string {
+	runes := []rune(s)
+	marked := make([]bool, len(runes))
+
+	lower, needle := []rune(strings.ToLower(s)), []rune(strings.ToLower(query))
+	if len(lower) == len(runes) && len(needle) > 0 {
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	queryTrigrams := Set(query)
+	for _, w := range words(runes) {
+		p := padded(w)
+		for i := 0; i+3 <= len(p); i++ {
+			if !queryTrigrams[string(p[i:i+3])] {
+				continue
+			}
+			// Позиция k в дополненном слове — символ k-2 слова; пробелы дополнения не подсвечиваются
+			for k := i; k < i+3; k++ {
+				if k >= 2 && k-2 < len(w.runes) {
+					marked[w.start+k-2] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		fragment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			b.WriteString("<mark>" + fragment + "</mark>")
+		} else {
+			b.WriteString(fragment)
+		}
+		i = j
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package trigram
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{s: "cat", want: []string{"  c", " ca", "at ", "cat"}},
+		{s: "Cat!", want: []string{"  c", " ca", "at ", "cat"}},
+		{s: "a-b", want: []string{"  a", "  b", " a ", " b "}},
+		{s: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			var got []string
+			for trigram := range Set(tt.s) {
+				got = append(got, trigram)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Set(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		// Значение из документации pg_trgm
+		{a: "word", b: "two words", want: 4.0 / 11},
+		{a: "Netflix", b: "netflix", want: 1},
+		{a: "Netflix", b: "Spotify", want: 0},
+		{a: "", b: "Netflix", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		s, query string
+		want     bool
+	}{
+		{s: "Netflix", query: "netflx", want: true},
+		{s: "Yandex Plus", query: "PLUS", want: true},
+		{s: "Yandex Plus", query: "x p", want: true},
+		{s: "Spotify", query: "netflix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s+"/"+tt.query, func(t *testing.T) {
+			if got := Match(tt.s, tt.query); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.s, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		s, query, want string
+	}{
+		{s: "Netflix", query: "net", want: "<mark>Net</mark>flix"},
+		{s: "Spotify Premium", query: "premium", want: "Spotify <mark>Premium</mark>"},
+		{s: "Yandex Plus", query: "x p", want: "Yande<mark>x P</mark>lus"},
+		{s: "A&B <tv>", query: "zzz", want: "A&amp;B &lt;tv&gt;"},
+		{s: "Netflix", query: "", want: "Netflix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s+"/"+tt.query, func(t *testing.T) {
+			if got := Highlight(tt.s, tt.query); got != tt.want {
+				t.Errorf("Highlight(%q, %q) = %q, want %q", tt.s, tt.query, got, tt.want)
+			}
+		})
+	}
+}